@@ -176,6 +176,12 @@ export class ClaudeStreamClient {
         this.ui.streamFile('📝 Writing', target);
         this.filesProcessed++;
         break;
+
+      case 'Edit':
+        this.ui.streamFile('✏️ Editing', target, {
+          change: { old: args.old_string ?? '', new: args.new_string ?? '', partial: true }
+        });
+        break;
         
       case 'Grep':
         this.ui.streamFile('🔍 Searching', `${args.pattern} in ${args.path || '.'}`);
@@ -298,7 +298,14 @@ IMPORTANT: Return ONLY the JSON array, no other text or markdown.
   /**
    * Write audit report to file
    */
-  async writeReport(report: AuditReport, outputPath: string) {
+  async writeReport(
+    report: AuditReport,
+    outputPath: string,
+    write: (filePath: string, content: string) => Promise<boolean> = async (filePath, content) => {
+      await fs.writeFile(filePath, content);
+      return true;
+    }
+  ): Promise<boolean> {
     const content = `# Documentation Audit Report
 
 Generated: ${report.timestamp.toLocaleString()}
@@ -328,6 +335,6 @@ ${report.issues.filter(i => i.severity === 'low').map(i =>
 ${report.recommendations.map(r => `- ${r}`).join('\n')}
 `;
     
-    await fs.writeFile(outputPath, content);
+    return write(outputPath, content);
   }
 }
\ No newline at end of file
@@ -2,6 +2,12 @@ import { spawn } from 'child_process';
 import * as readline from 'readline';
 import { TUIAdapter } from './TUIAdapter';
 
+// Tools that change files or run commands. Under DOCUMENTOR_APPROVE_WRITES
+// Claude may not use them: it only reads, and the generator writes every
+// doc through the TUI's approval gate.
+const WRITE_TOOLS = ['Write', 'Edit', 'MultiEdit', 'NotebookEdit', 'Bash'];
+const READ_ONLY_TOOLS = ['Read', 'Glob', 'Grep', 'LS'];
+
 /**
  * Streaming Claude query with real JSON event streaming
  */
@@ -21,10 +27,17 @@ export async function streamingClaudeQuery(
     const args: string[] = [
       '--print',  // Required for output-format
       '--verbose',  // Required for stream-json
-      '--output-format', 'stream-json',  // JSON streaming
-      '--dangerously-skip-permissions'  // Allow Claude to access all files
+      '--output-format', 'stream-json'  // JSON streaming
     ];
     
+    if (process.env.DOCUMENTOR_APPROVE_WRITES) {
+      // Read only: nothing may be written without the TUI's approval
+      tools = (tools && tools.length > 0 ? tools : READ_ONLY_TOOLS).filter(tool => !WRITE_TOOLS.includes(tool));
+      args.push('--disallowedTools', ...WRITE_TOOLS);
+    } else {
+      args.push('--dangerously-skip-permissions');  // Allow Claude to access all files
+    }
+    
     // Add allowed tools if specified
     if (tools && tools.length > 0) {
       args.push('--allowedTools');
@@ -227,7 +240,9 @@ function handleToolCall(event: any, display: TUIAdapter, timestamp: string, file
   switch (tool) {
     case 'str_replace_based_edit_tool':
     case 'Edit':
-      display.streamFile('[EDIT]', target);
+      display.streamFile('[EDIT]', target, args.old_string !== undefined ? {
+        change: { old: args.old_string, new: args.new_string ?? '', partial: true }
+      } : undefined);
       break;
       
     case 'read_file':
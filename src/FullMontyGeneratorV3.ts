@@ -20,6 +20,7 @@ export interface FullMontyReport {
   projectType: 'single' | 'multi-tool' | 'monorepo' | 'collection';
   subProjects: number;
   documentsGenerated: number;
+  written: string[]; // every doc written, for the TUI's review page
   quality: {
     codeQuality: number;
     documentationCoverage: number;
@@ -28,6 +29,25 @@ export interface FullMontyReport {
   };
 }
 
+/**
+ * The docs the documentation phase writes. plannedDocs lists the same
+ * files, so the plan sent to the TUI follows any change here.
+ */
+const SINGLE_PROJECT_DOCS = {
+  readme: 'README.md',
+  usage: 'USAGE.md',
+  technical: 'TECHNICAL.md',
+  api: 'API.md',
+  examples: 'EXAMPLES.md'
+};
+const SUBPROJECT_DOCS = {
+  readme: 'README.md',
+  usage: 'usage.md',
+  technical: 'technical.md',
+  examples: 'examples.md'
+};
+const MULTI_PROJECT_INDEX = 'PROJECT-INDEX.md';
+
 export class FullMontyGeneratorV3 {
   private config: ConfigManager;
   private ui: TUIAdapter;
@@ -36,6 +56,7 @@ export class FullMontyGeneratorV3 {
   private streamer: StreamingReporter;
   private report: FullMontyReport;
   private frontmatterValidator: ImprovedFrontmatterGenerator;
+  private outputDir = ''; // where the docs are written, for the summary
   
   constructor(verbose: boolean = false) {
     this.config = new ConfigManager();
@@ -66,6 +87,7 @@ export class FullMontyGeneratorV3 {
         projectType: 'single',
         subProjects: 0,
         documentsGenerated: 0,
+        written: [],
         quality: {
           codeQuality: 0,
           documentationCoverage: 0,
@@ -128,6 +150,9 @@ export class FullMontyGeneratorV3 {
         
         await lock.updateLock({ currentPhase: 'analysis', progress: 20 });
         
+        // The docs about to be written, pending in the TUI's file tree
+        this.ui.planFiles(this.plannedDocs(structure, targetPath, config));
+        
         // Initialize Obsidian linker
         const linker = new ObsidianLinker(config.obsidianVaultPath, projectName);
         
@@ -175,11 +200,10 @@ export class FullMontyGeneratorV3 {
         this.ui.updateTask('tag-review', 60, 'Generating tag report...');
         const tagReport = tagManager.generateTagReport();
         const tagReportPath = path.join(config.obsidianVaultPath, projectName, 'TAG-REPORT.md');
-        await fs.writeFile(tagReportPath, tagReport);
-        this.ui.streamFile('Writing', 'TAG-REPORT.md');
+        await this.writeDoc(tagReportPath, tagReport);
         
         this.ui.updateTask('tag-review', 80, 'Saving tag registry...');
-        await tagManager.saveRegistry();
+        await tagManager.saveRegistry((filePath, content) => this.writeDoc(filePath, content));
         
         this.ui.updateTask('tag-review', 100, 'Tag consolidation complete');
         this.ui.completeTask('tag-review', true);
@@ -189,9 +213,7 @@ export class FullMontyGeneratorV3 {
         this.ui.updatePhase('Index Generation');
         
         this.ui.updateTask('indexes', 50, 'Generating index files...');
-        await linker.saveIndexes();
-        this.ui.streamFile('Writing', 'INDEX.md');
-        this.ui.streamFile('Writing', 'TAG-INDEX.md');
+        await linker.saveIndexes((filePath, content) => this.writeDoc(filePath, content));
         
         this.ui.updateTask('indexes', 100, 'Indexes created');
         this.ui.completeTask('indexes', true);
@@ -219,8 +241,7 @@ export class FullMontyGeneratorV3 {
         await fs.mkdir(reportDir, { recursive: true });
         
         const reportPath = path.join(reportDir, `report_${timestamp}.md`);
-        await fs.writeFile(reportPath, reportData);
-        this.ui.streamFile('Writing', `report_${timestamp}.md`);
+        const reportWritten = await this.writeDoc(reportPath, reportData);
         
         this.ui.updateTask('report-gen', 100, 'Report generated');
         this.ui.completeTask('report-gen', true);
@@ -240,8 +261,7 @@ export class FullMontyGeneratorV3 {
         
         if (auditReport.issuesFound > 0) {
           this.ui.log('warning', `Found ${auditReport.issuesFound} documentation issues`);
-          await auditor.writeReport(auditReport, path.join(docsPath, 'AUDIT_REPORT.md'));
-          this.ui.streamFile('Writing', 'AUDIT_REPORT.md');
+          await auditor.writeReport(auditReport, path.join(docsPath, 'AUDIT_REPORT.md'), (filePath, content) => this.writeDoc(filePath, content));
         } else {
           this.ui.log('success', 'Documentation audit passed - no issues found!');
         }
@@ -260,10 +280,12 @@ export class FullMontyGeneratorV3 {
         this.report.duration = Date.now() - startTime;
         
         // Show final summary
-        this.ui.showSummary(this.report);
+        this.ui.showSummary({ ...this.report, outputDir: this.outputDir });
         
         this.ui.updatePhase('Complete');
-        this.ui.log('success', `Report saved to ${reportPath}`);
+        if (reportWritten) {
+          this.ui.log('success', `Report saved to ${reportPath}`);
+        }
         
         return this.report;
         
@@ -293,6 +315,9 @@ export class FullMontyGeneratorV3 {
     const totalProjects = structure.subProjects.length;
     let processedProjects = 0;
     
+    // The index and the subprojects' folders have no closer common parent
+    this.outputDir = config.obsidianVaultPath;
+    
     // Create main index first
     await this.createMultiProjectIndex(structure, config, linker);
     
@@ -312,10 +337,10 @@ export class FullMontyGeneratorV3 {
       this.ui.createTask(taskId, `Documenting ${subProject.name}`, 100);
       
       // Document the subproject
-      await this.documentSubProject(subProject, config, linker, taskId, tagManager);
+      const written = await this.documentSubProject(subProject, config, linker, taskId, tagManager);
       
       this.ui.completeTask(taskId, true);
-      this.report.documentsGenerated += 4; // README, usage, technical, examples
+      this.report.documentsGenerated += written;
     }
   }
   
@@ -333,15 +358,16 @@ export class FullMontyGeneratorV3 {
     
     const projectName = path.basename(targetPath);
     // IMPORTANT: Always save to obsidian_vault, NEVER in the project directory
-    const outputPath = path.join(config.obsidianVaultPath, 'docs', projectName);
+    const outputPath = this.singleProjectOutputPath(targetPath, config);
     await fs.mkdir(outputPath, { recursive: true });
+    this.outputDir = outputPath;
     
     // Start Generation Phase
     this.phaseManager.startPhase(PhaseType.GENERATION);
     this.phaseManager.startTask('gen-readme');
     
     // 1. Generate README
-    this.phaseManager.reportDocumentOperation('creating', 'README.md', 0);
+    this.phaseManager.reportDocumentOperation('creating', SINGLE_PROJECT_DOCS.readme, 0);
     this.ui.streamAnalysis('Claude', 'Analyzing project for README generation...');
     
     const readmePrompt = `
@@ -364,7 +390,7 @@ Format as professional markdown documentation.
       undefined,  // no specific tools
       targetPath  // Pass the project path dynamically
     );
-    this.phaseManager.reportDocumentOperation('writing', 'README.md', 100);
+    this.phaseManager.reportDocumentOperation('writing', SINGLE_PROJECT_DOCS.readme, 100);
     
     const readmeTags = await tagManager.processDocumentTags(
       ['readme', 'documentation', projectName],
@@ -401,12 +427,12 @@ ${linker.generateBacklinksSection(readmeDoc)}
       throw new Error('Frontmatter validation failed - all fields must be present!');
     }
     
-    await fs.writeFile(path.join(outputPath, 'README.md'), readmeContent);
-    this.ui.streamFile('Writing', 'README.md', { size: readmeContent.length });
-    this.report.documentsGenerated++;
+    if (await this.writeDoc(path.join(outputPath, SINGLE_PROJECT_DOCS.readme), readmeContent)) {
+      this.report.documentsGenerated++;
+    }
     
     // 2. Generate Usage Guide
-    this.ui.updateTask('main-docs', 30, 'Creating usage guide...', 'USAGE.md');
+    this.ui.updateTask('main-docs', 30, 'Creating usage guide...', SINGLE_PROJECT_DOCS.usage);
     this.ui.streamAnalysis('Claude', 'Generating usage documentation...');
     
     const usagePrompt = `
@@ -427,7 +453,7 @@ Create a comprehensive usage guide for ${projectName}:
       undefined,  // no specific tools
       targetPath  // Pass the project path dynamically
     );
-    this.phaseManager.reportDocumentOperation('writing', 'USAGE.md', 100);
+    this.phaseManager.reportDocumentOperation('writing', SINGLE_PROJECT_DOCS.usage, 100);
     
     const usageTags = await tagManager.processDocumentTags(
       ['usage', 'guide', 'howto', projectName],
@@ -463,12 +489,12 @@ ${linker.generateBacklinksSection(usageDoc)}
       throw new Error('Frontmatter validation failed - all fields must be present!');
     }
     
-    await fs.writeFile(path.join(outputPath, 'USAGE.md'), usageContent);
-    this.ui.streamFile('Writing', 'USAGE.md', { size: usageContent.length });
-    this.report.documentsGenerated++;
+    if (await this.writeDoc(path.join(outputPath, SINGLE_PROJECT_DOCS.usage), usageContent)) {
+      this.report.documentsGenerated++;
+    }
     
     // 3. Generate Technical Documentation
-    this.ui.updateTask('main-docs', 50, 'Creating technical docs...', 'TECHNICAL.md');
+    this.ui.updateTask('main-docs', 50, 'Creating technical docs...', SINGLE_PROJECT_DOCS.technical);
     this.ui.streamAnalysis('Claude', 'Analyzing architecture...');
     
     const technicalPrompt = `
@@ -524,12 +550,12 @@ ${linker.generateBacklinksSection(techDoc)}
       throw new Error('Frontmatter validation failed - all fields must be present!');
     }
     
-    await fs.writeFile(path.join(outputPath, 'TECHNICAL.md'), techContent);
-    this.ui.streamFile('Writing', 'TECHNICAL.md', { size: techContent.length });
-    this.report.documentsGenerated++;
+    if (await this.writeDoc(path.join(outputPath, SINGLE_PROJECT_DOCS.technical), techContent)) {
+      this.report.documentsGenerated++;
+    }
     
     // 4. Generate API Documentation
-    this.ui.updateTask('main-docs', 70, 'Generating API docs...', 'API.md');
+    this.ui.updateTask('main-docs', 70, 'Generating API docs...', SINGLE_PROJECT_DOCS.api);
     this.ui.streamAnalysis('Claude', 'Documenting API...');
     
     const apiPrompt = `
@@ -583,12 +609,12 @@ ${linker.generateBacklinksSection(apiDoc)}
       throw new Error('Frontmatter validation failed - all fields must be present!');
     }
     
-    await fs.writeFile(path.join(outputPath, 'API.md'), apiContent);
-    this.ui.streamFile('Writing', 'API.md', { size: apiContent.length });
-    this.report.documentsGenerated++;
+    if (await this.writeDoc(path.join(outputPath, SINGLE_PROJECT_DOCS.api), apiContent)) {
+      this.report.documentsGenerated++;
+    }
     
     // 5. Generate Examples
-    this.ui.updateTask('main-docs', 90, 'Creating examples...', 'EXAMPLES.md');
+    this.ui.updateTask('main-docs', 90, 'Creating examples...', SINGLE_PROJECT_DOCS.examples);
     this.ui.streamAnalysis('Claude', 'Generating code examples...');
     
     const examplesPrompt = `
@@ -642,9 +668,9 @@ ${linker.generateBacklinksSection(exampleDoc)}
       throw new Error('Frontmatter validation failed - all fields must be present!');
     }
     
-    await fs.writeFile(path.join(outputPath, 'EXAMPLES.md'), exampleContent);
-    this.ui.streamFile('Writing', 'EXAMPLES.md', { size: exampleContent.length });
-    this.report.documentsGenerated++;
+    if (await this.writeDoc(path.join(outputPath, SINGLE_PROJECT_DOCS.examples), exampleContent)) {
+      this.report.documentsGenerated++;
+    }
     
     this.ui.updateTask('main-docs', 100, 'Documentation complete!');
     this.ui.completeTask('main-docs', true);
@@ -661,9 +687,9 @@ ${linker.generateBacklinksSection(exampleDoc)}
     linker: ObsidianLinker,
     taskId: string,
     tagManager: SmartTagManager
-  ): Promise<void> {
+  ): Promise<number> {
     const projectName = path.basename(config.obsidianVaultPath);
-    const outputPath = path.join(config.obsidianVaultPath, projectName, subProject.name);
+    const outputPath = this.subProjectOutputPath(subProject, config);
     await fs.mkdir(outputPath, { recursive: true });
     
     this.ui.streamAnalysis('Subproject', `Documenting ${subProject.name}: ${subProject.description}`);
@@ -723,20 +749,30 @@ ${linker.generateBacklinksSection(readmeDoc)}
       throw new Error('Frontmatter validation failed - all fields must be present!');
     }
     
-    await fs.writeFile(path.join(outputPath, 'README.md'), readmeContent);
-    this.ui.streamFile('Writing', `${subProject.name}/README.md`);
+    // Count what was written: a doc rejected on the approval page was not
+    let written = 0;
+    if (await this.writeDoc(path.join(outputPath, SUBPROJECT_DOCS.readme), readmeContent)) {
+      written++;
+    }
     
     // Continue with other documents...
-    this.ui.updateTask(taskId, 50, 'Creating usage guide...', `${subProject.name}/usage.md`);
-    await this.createUsageGuide(subProject, outputPath, linker, tagManager);
+    this.ui.updateTask(taskId, 50, 'Creating usage guide...', `${subProject.name}/${SUBPROJECT_DOCS.usage}`);
+    if (await this.createUsageGuide(subProject, outputPath, linker, tagManager)) {
+      written++;
+    }
     
-    this.ui.updateTask(taskId, 75, 'Creating technical docs...', `${subProject.name}/technical.md`);
-    await this.createTechnicalDocs(subProject, outputPath, linker, tagManager);
+    this.ui.updateTask(taskId, 75, 'Creating technical docs...', `${subProject.name}/${SUBPROJECT_DOCS.technical}`);
+    if (await this.createTechnicalDocs(subProject, outputPath, linker, tagManager)) {
+      written++;
+    }
     
-    this.ui.updateTask(taskId, 90, 'Creating examples...', `${subProject.name}/examples.md`);
-    await this.createExamples(subProject, outputPath, linker, tagManager);
+    this.ui.updateTask(taskId, 90, 'Creating examples...', `${subProject.name}/${SUBPROJECT_DOCS.examples}`);
+    if (await this.createExamples(subProject, outputPath, linker, tagManager)) {
+      written++;
+    }
     
     this.ui.updateTask(taskId, 100, `${subProject.name} documented`);
+    return written;
   }
   
   /**
@@ -747,7 +783,7 @@ ${linker.generateBacklinksSection(readmeDoc)}
     outputPath: string,
     linker: ObsidianLinker,
     tagManager: SmartTagManager
-  ): Promise<void> {
+  ): Promise<boolean> {
     const prompt = `
       Create a comprehensive usage guide for ${subProject.name}:
       - Type: ${subProject.type}
@@ -802,8 +838,7 @@ ${linker.generateBacklinksSection(doc)}
       throw new Error('Frontmatter validation failed - all fields must be present!');
     }
     
-    await fs.writeFile(path.join(outputPath, 'usage.md'), content);
-    this.ui.streamFile('Writing', `${subProject.name}/usage.md`, { size: content.length });
+    return this.writeDoc(path.join(outputPath, SUBPROJECT_DOCS.usage), content);
   }
   
   private async createTechnicalDocs(
@@ -811,7 +846,7 @@ ${linker.generateBacklinksSection(doc)}
     outputPath: string,
     linker: ObsidianLinker,
     tagManager: SmartTagManager
-  ): Promise<void> {
+  ): Promise<boolean> {
     const prompt = `
       Create technical documentation for ${subProject.name}:
       - Type: ${subProject.type}
@@ -867,8 +902,7 @@ ${linker.generateBacklinksSection(doc)}
       throw new Error('Frontmatter validation failed - all fields must be present!');
     }
     
-    await fs.writeFile(path.join(outputPath, 'technical.md'), content);
-    this.ui.streamFile('Writing', `${subProject.name}/technical.md`, { size: content.length });
+    return this.writeDoc(path.join(outputPath, SUBPROJECT_DOCS.technical), content);
   }
   
   private async createExamples(
@@ -876,7 +910,7 @@ ${linker.generateBacklinksSection(doc)}
     outputPath: string,
     linker: ObsidianLinker,
     tagManager: SmartTagManager
-  ): Promise<void> {
+  ): Promise<boolean> {
     const prompt = `
       Create practical examples for ${subProject.name}:
       - Type: ${subProject.type}
@@ -930,17 +964,15 @@ ${linker.generateBacklinksSection(doc)}
       throw new Error('Frontmatter validation failed - all fields must be present!');
     }
     
-    await fs.writeFile(path.join(outputPath, 'examples.md'), content);
-    this.ui.streamFile('Writing', `${subProject.name}/examples.md`, { size: content.length });
+    return this.writeDoc(path.join(outputPath, SUBPROJECT_DOCS.examples), content);
   }
   
   private async createMultiProjectIndex(
     structure: any,
     config: any,
     linker: ObsidianLinker
-  ): Promise<void> {
+  ): Promise<boolean> {
     const projectName = path.basename(structure.rootPath);
-    const outputPath = path.join(config.obsidianVaultPath, projectName);
     
     let indexContent = `---
 title: ${projectName} Multi-Project Index
@@ -974,8 +1006,7 @@ ${structure.reasoning || 'Multi-project repository'}
     indexContent += `- [[TAG-INDEX|Browse by Tags]]\n`;
     indexContent += `- [[INDEX|Main Index]]\n`;
     
-    await fs.writeFile(path.join(outputPath, 'PROJECT-INDEX.md'), indexContent);
-    this.ui.streamFile('Writing', 'PROJECT-INDEX.md', { size: indexContent.length });
+    return this.writeDoc(this.multiProjectIndexPath(structure, config), indexContent);
   }
   
   /**
@@ -1085,6 +1116,81 @@ _This report was generated by Claude AI analyzing the documentation run._
     return finalReport;
   }
   
+  /**
+   * The docs the documentation phase writes, by absolute path as writeDoc
+   * reports them
+   */
+  private plannedDocs(structure: any, targetPath: string, config: any): string[] {
+    if (!structure.isMultiProject) {
+      const outputPath = this.singleProjectOutputPath(targetPath, config);
+      return Object.values(SINGLE_PROJECT_DOCS).map(name => path.join(outputPath, name));
+    }
+    const docs = [this.multiProjectIndexPath(structure, config)];
+    for (const subProject of structure.subProjects) {
+      const outputPath = this.subProjectOutputPath(subProject, config);
+      docs.push(...Object.values(SUBPROJECT_DOCS).map(name => path.join(outputPath, name)));
+    }
+    return docs;
+  }
+  
+  /**
+   * Where the docs of a single project go
+   */
+  private singleProjectOutputPath(targetPath: string, config: any): string {
+    return path.join(config.obsidianVaultPath, 'docs', path.basename(targetPath));
+  }
+  
+  /**
+   * Where the docs of a subproject go
+   */
+  private subProjectOutputPath(subProject: SubProject, config: any): string {
+    return path.join(config.obsidianVaultPath, path.basename(config.obsidianVaultPath), subProject.name);
+  }
+  
+  /**
+   * The index of a multi-project repository
+   */
+  private multiProjectIndexPath(structure: any, config: any): string {
+    return path.join(config.obsidianVaultPath, path.basename(structure.rootPath), MULTI_PROJECT_INDEX);
+  }
+  
+  /**
+   * Write a file of the output, once the TUI approved it if write approval
+   * is on. Every write of the run goes through here: under write approval
+   * Claude itself runs read only. Returns false if the file was not written.
+   */
+  private async writeDoc(filePath: string, content: string): Promise<boolean> {
+    const approval = await this.ui.requestApproval('Write', filePath, content);
+    if (approval.decision === 'reject') {
+      this.ui.log('warning', `Not writing ${path.basename(filePath)}: rejected`);
+      return false;
+    }
+    // Edited content must pass the frontmatter validation the proposed doc passed
+    if (approval.decision === 'edit' &&
+        this.frontmatterValidator.validateFrontmatter(content) &&
+        !this.frontmatterValidator.validateFrontmatter(approval.content)) {
+      this.ui.logError('Write approval', `Not writing ${path.basename(filePath)}: the edited frontmatter is missing required fields`);
+      return false;
+    }
+    let old = '';
+    let existed = false;
+    try {
+      old = await fs.readFile(filePath, 'utf-8');
+      existed = true;
+    } catch {
+      // A new file
+    }
+    await fs.writeFile(filePath, approval.content);
+    this.report.written.push(filePath);
+    // The absolute path and what was replaced, so the TUI's review can revert
+    // the write without guessing which file a bare name meant
+    this.ui.streamFile('Writing', filePath, {
+      size: approval.content.length,
+      change: { old, new: approval.content, existed }
+    });
+    return true;
+  }
+
   /**
    * Format duration helper
    */
@@ -439,17 +439,19 @@ ${this.getDocumentsByTag('core').map(d => `- [[${d.path}|${d.title}]]`).join('\n
   }
   
   // Save index files
-  async saveIndexes(): Promise<void> {
+  async saveIndexes(
+    write: (filePath: string, content: string) => Promise<unknown> = (filePath, content) => fs.writeFile(filePath, content)
+  ): Promise<void> {
     const indexPath = path.join(this.vaultPath, this.projectName);
     await fs.mkdir(indexPath, { recursive: true });
     
     // Save tag index
     const tagIndex = await this.generateTagIndex();
-    await fs.writeFile(path.join(indexPath, 'tag-index.md'), tagIndex);
+    await write(path.join(indexPath, 'tag-index.md'), tagIndex);
     
     // Save document map
     const docMap = await this.generateDocumentMap();
-    await fs.writeFile(path.join(indexPath, 'document-map.md'), docMap);
+    await write(path.join(indexPath, 'document-map.md'), docMap);
   }
   
   /**
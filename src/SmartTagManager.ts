@@ -576,7 +576,9 @@ export class SmartTagManager {
   /**
    * Save tag registry for future use
    */
-  async saveRegistry(): Promise<void> {
+  async saveRegistry(
+    write: (filePath: string, content: string) => Promise<unknown> = (filePath, content) => fs.writeFile(filePath, content)
+  ): Promise<void> {
     const registryPath = path.join(this.vaultPath, '.tag-registry.json');
     
     const data = {
@@ -586,7 +588,7 @@ export class SmartTagManager {
       lastUpdated: new Date().toISOString()
     };
     
-    await fs.writeFile(registryPath, JSON.stringify(data, null, 2));
+    await write(registryPath, JSON.stringify(data, null, 2));
   }
   
   /**
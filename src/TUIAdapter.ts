@@ -21,9 +21,33 @@ export class TUIAdapter extends EventEmitter {
   private tasks: Map<string, any> = new Map();
   private lockFilePath: string = '';
   private lockUpdateInterval: NodeJS.Timeout | null = null;
+  private pendingReplies: Map<string, (reply: any) => void> = new Map();
+  private replyReader: any = null;
+  private requestCount: number = 0;
 
   constructor() {
     super();
+    TUIAdapter.checkRepliesChannel();
+  }
+
+  /**
+   * A return channel other than stdin must be a FIFO: a regular file is read
+   * up to its end once, so answers the TUI adds later would never arrive and
+   * the first request would wait forever.
+   */
+  private static checkRepliesChannel() {
+    const channel = process.env.DOCUMENTOR_TUI_REPLIES;
+    if (!channel || channel === 'stdin') return;
+    const fs = require('fs');
+    let stat;
+    try {
+      stat = fs.statSync(channel);
+    } catch {
+      throw new Error(`DOCUMENTOR_TUI_REPLIES: ${channel} does not exist, create it with mkfifo`);
+    }
+    if (!stat.isFIFO()) {
+      throw new Error(`DOCUMENTOR_TUI_REPLIES: ${channel} is not a FIFO, create one with mkfifo`);
+    }
   }
 
   private send(message: TUIMessage) {
@@ -167,9 +191,19 @@ export class TUIAdapter extends EventEmitter {
     this.send({
       type: 'tool',
       tool: action,
-      content: fileName
+      content: fileName,
+      // What an Edit or Write replaced, for the TUI's diff page
+      change: options?.change
+    });
+    // Other options like { size: number } are ignored for now
+  }
+
+  planFiles(files: string[]) {
+    // The TUI lists these as pending in its file tree
+    this.send({
+      type: 'plan',
+      plan: files
     });
-    // Options like { size: number } are ignored for now
   }
 
   logInfo(title: string, message?: string) {
@@ -248,18 +282,93 @@ export class TUIAdapter extends EventEmitter {
     });
   }
 
-  requestPassword(prompt: string, context?: string): Promise<string> {
-    const requestId = `pwd-${Date.now()}`;
-    this.send({
+  /**
+   * Start reading the TUI's answers, one JSON object per line. The TUI sets
+   * DOCUMENTOR_TUI_REPLIES to "stdin" when it launches us, or to the FIFO it
+   * was told to write them to. Returns false without a return channel.
+   */
+  private listenForReplies(): boolean {
+    if (this.replyReader) return true;
+    const channel = process.env.DOCUMENTOR_TUI_REPLIES;
+    if (!channel) return false;
+
+    const fs = require('fs');
+    const readline = require('readline');
+    const input = channel === 'stdin' ? process.stdin : fs.createReadStream(channel);
+    this.replyReader = readline.createInterface({ input });
+    this.replyReader.on('line', (line: string) => {
+      let reply: any;
+      try {
+        reply = JSON.parse(line);
+      } catch {
+        return; // not an answer
+      }
+      const resolve = this.pendingReplies.get(reply.requestId);
+      if (resolve) {
+        this.pendingReplies.delete(reply.requestId);
+        resolve(reply);
+      }
+      if (this.pendingReplies.size === 0) {
+        // Don't keep the process alive waiting for answers nobody asked for
+        this.replyReader.pause();
+      }
+    });
+    return true;
+  }
+
+  /**
+   * Send a request and wait for the TUI's answer, or null without a return channel
+   */
+  private request(message: TUIMessage): Promise<any> | null {
+    if (!this.listenForReplies()) return null;
+    return new Promise(resolve => {
+      this.pendingReplies.set(message.requestId, resolve);
+      this.replyReader.resume();
+      this.send(message);
+    });
+  }
+
+  async requestPassword(prompt: string, context?: string): Promise<string> {
+    const message = {
       type: 'password_request',
-      requestId: requestId,
+      requestId: `pwd-${Date.now()}-${++this.requestCount}`,
       prompt: prompt,
       context: context
+    };
+    const reply = this.request(message);
+    if (!reply) {
+      // Nobody can answer, show the prompt anyway
+      this.send(message);
+      return '';
+    }
+    const answer = await reply;
+    return answer.cancelled ? '' : answer.password;
+  }
+
+  /**
+   * Ask the TUI whether a file may be written with the given content. Only
+   * asks when DOCUMENTOR_APPROVE_WRITES is set; the answer may replace the
+   * content. Without a return channel nobody can approve, so writes are
+   * rejected.
+   */
+  async requestApproval(tool: string, filePath: string, content: string, context?: string): Promise<{ decision: string; content: string }> {
+    if (!process.env.DOCUMENTOR_APPROVE_WRITES) {
+      return { decision: 'approve', content };
+    }
+    const reply = this.request({
+      type: 'approval_request',
+      requestId: `apr-${Date.now()}-${++this.requestCount}`,
+      tool,
+      path: filePath,
+      content,
+      context
     });
-    
-    // For now, return empty string - will need to implement proper IPC
-    // in the future to receive password response from Go TUI
-    return Promise.resolve('');
+    if (!reply) {
+      this.logError('Write approval', `DOCUMENTOR_APPROVE_WRITES is set but there is no return channel, not writing ${filePath}`);
+      return { decision: 'reject', content };
+    }
+    const answer = await reply;
+    return { decision: answer.decision, content: answer.decision === 'edit' ? answer.content : content };
   }
 
   // Compatibility methods for minimal disruption
@@ -380,10 +489,22 @@ export class TUIAdapter extends EventEmitter {
   }
 
   showSummary(summary: any) {
+    // The TUI shows this on its summary page and fills in what is missing
+    // (files, warnings, errors, phase timings, elapsed) from its own counts
     this.send({
-      type: 'log',
-      level: 'success',
-      content: JSON.stringify(summary, null, 2)
+      type: 'summary',
+      summary: {
+        filesDocumented: summary.filesDocumented ?? summary.documentsGenerated,
+        filesFailed: summary.filesFailed,
+        phases: summary.phases,
+        warnings: summary.warnings,
+        errors: summary.errors,
+        tokens: summary.tokens,
+        cost: summary.cost,
+        outputDir: summary.outputDir,
+        elapsedSeconds: summary.elapsedSeconds ?? (summary.duration ? summary.duration / 1000 : undefined),
+        written: summary.written
+      }
     });
   }
 
@@ -0,0 +1,457 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Action is something the user can trigger from the keyboard or the button row
+type Action struct {
+	ID      string
+	Label   string   // shown in the help overlay
+	Button  string   // button row label, empty if the action has no button
+	Icon    string   // button row label of the compact layout
+	Mode    string   // view the action switches to, highlights its button
+	Keys    []string // default bindings
+	VimKeys []string // extra bindings of the vim keymap
+	Enabled func(t *TUI) bool
+	Run     func(t *TUI)
+}
+
+// actions is the registry of every action, in button row and help order
+var actions []*Action
+
+// Filled in init because showHelp itself walks the registry
+func init() {
+	actions = []*Action{
+		{ID: "normal", Label: "Switch to the logs view", Button: "Normal", Icon: "\uf15c", Mode: "normal", Keys: []string{"n"},
+			Run: func(t *TUI) { t.switchView("normal") }},
+		{ID: "debug", Label: "Switch to the debug view", Button: "Debug", Icon: "\uf188", Mode: "debug", Keys: []string{"d"},
+			Run: func(t *TUI) { t.switchView("debug") }},
+		{ID: "raw", Label: "Switch to the raw API view", Button: "Raw", Icon: "\uf1eb", Mode: "raw", Keys: []string{"r"},
+			Run: func(t *TUI) { t.switchView("raw") }},
+		{ID: "clear", Label: "Clear the current view", Button: "Clear", Icon: "\uf12d", Keys: []string{"c"},
+			Enabled: func(t *TUI) bool {
+				// The button row is drawn before the views exist
+				p := t.projections[t.viewMode]
+				return p != nil && p.lines > 0
+			},
+			Run: func(t *TUI) { t.clearCurrentView() }},
+		{ID: "filter", Label: "Filter logs", Button: "Filter", Icon: "\uf0b0", Keys: []string{"f"}, VimKeys: []string{"/"},
+			Run: func(t *TUI) { t.showFilterDialog() }},
+		{ID: "export", Label: "Export logs", Button: "Export", Icon: "\uf019", Keys: []string{"e"},
+			Enabled: func(t *TUI) bool { return t.events.Len() > 0 },
+			Run:     func(t *TUI) { t.exportLogs() }},
+		{ID: "summary", Label: "Show the run summary", Button: "Summary", Icon: "\uf080", Keys: []string{"s"},
+			Enabled: func(t *TUI) bool { return t.summary != nil || t.state.final() },
+			Run:     func(t *TUI) { t.showSummary() }},
+		{ID: "problems", Label: "Show or hide the problems panel", Keys: []string{"w"},
+			Run: func(t *TUI) { t.toggleProblems() }},
+		{ID: "files", Label: "Show or hide the file tree", Keys: []string{"t"},
+			Run: func(t *TUI) { t.toggleFiles() }},
+		{ID: "preview", Label: "Preview the selected file or the last doc written", Keys: []string{"o"},
+			Run: func(t *TUI) { t.previewSelected() }},
+		{ID: "diff", Label: "Show what the selected Write or Edit call changed", Keys: []string{"="},
+			Run: func(t *TUI) { t.showDiffs() }},
+		{ID: "review", Label: "Review the files the run wrote", Keys: []string{"i"},
+			Run: func(t *TUI) { t.showReview() }},
+		{ID: "vault", Label: "Browse the notes, tags and links of the output folder", Keys: []string{"b"},
+			Run: func(t *TUI) { t.showVault() }},
+		{ID: "git", Label: "Show the git status and commit the docs", Keys: []string{"Ctrl+G"},
+			Run: func(t *TUI) { t.showGit() }},
+		{ID: "approvals", Label: "Answer the waiting write approvals", Keys: []string{"a"},
+			Run: func(t *TUI) { t.showApprovals() }},
+		{ID: "select", Label: "Select lines to copy", Keys: []string{"v"},
+			Run: func(t *TUI) { t.startSelection() }},
+		{ID: "copy", Label: "Copy the clicked line", Keys: []string{"y"},
+			Run: func(t *TUI) { t.copyHighlighted() }},
+		{ID: "copyFile", Label: "Copy the path of the current file", Keys: []string{"Y"},
+			Run: func(t *TUI) { t.copyCurrentFile() }},
+		{ID: "mouse", Label: "Turn mouse support on or off", Keys: []string{"m"},
+			Run: func(t *TUI) { t.toggleMouse() }},
+		{ID: "quit", Label: "Quit", Button: "Quit", Icon: "\uf011", Keys: []string{"q", "Esc"},
+			Run: func(t *TUI) { t.app.Stop() }},
+		{ID: "help", Label: "Show this help", Keys: []string{"?"},
+			Run: func(t *TUI) { t.showHelp() }},
+		{ID: "focus", Label: "Move focus between the view and the buttons", Keys: []string{"Tab"},
+			Run: func(t *TUI) { t.switchFocus() }},
+		{ID: "pageUp", Label: "Scroll up a page", Keys: []string{"PgUp"}, VimKeys: []string{"Ctrl+B"},
+			Run: func(t *TUI) { t.scrollCurrentView(-10) }},
+		{ID: "pageDown", Label: "Scroll down a page", Keys: []string{"PgDn"}, VimKeys: []string{"Ctrl+F"},
+			Run: func(t *TUI) { t.scrollCurrentView(10) }},
+		{ID: "halfPageUp", Label: "Scroll up half a page", VimKeys: []string{"Ctrl+U"},
+			Run: func(t *TUI) { t.scrollCurrentView(-t.halfPage()) }},
+		{ID: "halfPageDown", Label: "Scroll down half a page", VimKeys: []string{"Ctrl+D"},
+			Run: func(t *TUI) { t.scrollCurrentView(t.halfPage()) }},
+		{ID: "lineUp", Label: "Scroll up a line", VimKeys: []string{"k"},
+			Run: func(t *TUI) { t.scrollCurrentView(-1) }},
+		{ID: "lineDown", Label: "Scroll down a line", VimKeys: []string{"j"},
+			Run: func(t *TUI) { t.scrollCurrentView(1) }},
+		{ID: "top", Label: "Jump to the oldest line", Keys: []string{"Home"}, VimKeys: []string{"g g"},
+			Run: func(t *TUI) { t.currentTextView().ScrollToBeginning() }},
+		{ID: "bottom", Label: "Jump to the newest line", Keys: []string{"End"}, VimKeys: []string{"G"},
+			Run: func(t *TUI) { t.currentTextView().ScrollToEnd() }},
+		{ID: "signal", Label: "Send SIGTERM/SIGKILL to the selected process", Keys: []string{"x"},
+			Enabled: func(t *TUI) bool { return t.canSignalSelected() },
+			Run:     func(t *TUI) { t.confirmSignal() }},
+		{ID: "password", Label: "Test the password modal", Keys: []string{"p"},
+			Run: func(t *TUI) { t.testSimplePasswordModal() }},
+	}
+}
+
+// chordTimeout is how long a started chord such as "g g" waits for its next key
+const chordTimeout = time.Second
+
+// findAction returns the registered action with the given id
+func findAction(id string) *Action {
+	for _, a := range actions {
+		if a.ID == id {
+			return a
+		}
+	}
+	return nil
+}
+
+// enabled reports whether the action can run right now
+func (a *Action) enabled(t *TUI) bool {
+	return a.Enabled == nil || a.Enabled(t)
+}
+
+// keyList is a list of key specs. The config accepts a single string too.
+type keyList []string
+
+func (k *keyList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*k = keyList{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("expected a key or a list of keys")
+	}
+	*k = list
+	return nil
+}
+
+// keyNames maps lower-cased key names to the canonical names used in
+// bindings, e.g. "pgup" -> "PgUp" and "ctrl-d" -> "Ctrl+D"
+var keyNames = func() map[string]string {
+	names := map[string]string{
+		"escape": "Esc",
+		"return": "Enter",
+		"space":  " ",
+	}
+	for _, name := range tcell.KeyNames {
+		canonical := strings.Replace(name, "Ctrl-", "Ctrl+", 1)
+		names[strings.ToLower(canonical)] = canonical
+		names[strings.ToLower(name)] = canonical
+	}
+	return names
+}()
+
+// parseKeySpec turns a binding like "q", "Ctrl+D" or "g g" into its
+// canonical key sequence
+func parseKeySpec(spec string) (string, error) {
+	fields := strings.Fields(spec)
+	if spec == " " {
+		fields = []string{"Space"}
+	}
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty key")
+	}
+
+	keys := make([]string, len(fields))
+	for i, field := range fields {
+		if len([]rune(field)) == 1 {
+			keys[i] = field
+			continue
+		}
+		lower := strings.ToLower(field)
+		if strings.HasPrefix(lower, "c-") || strings.HasPrefix(lower, "^") {
+			lower = "ctrl+" + strings.TrimLeft(lower[1:], "-")
+		}
+		if strings.HasPrefix(lower, "alt+") && len([]rune(field)) == 5 {
+			keys[i] = "Alt+" + field[4:]
+			continue
+		}
+		name, ok := keyNames[lower]
+		if !ok {
+			return "", fmt.Errorf("unknown key %q", field)
+		}
+		keys[i] = name
+	}
+	return strings.Join(keys, " "), nil
+}
+
+// eventKeyName returns the canonical name of a key event
+func eventKeyName(event *tcell.EventKey) string {
+	if event.Key() == tcell.KeyRune {
+		if event.Modifiers()&tcell.ModAlt != 0 {
+			return "Alt+" + string(event.Rune())
+		}
+		return string(event.Rune())
+	}
+	if name, ok := tcell.KeyNames[event.Key()]; ok {
+		return strings.Replace(name, "Ctrl-", "Ctrl+", 1)
+	}
+	return ""
+}
+
+// displayKey formats a key sequence for buttons and the help overlay
+func displayKey(seq string) string {
+	if seq == " " {
+		return "Space"
+	}
+	if len([]rune(seq)) == 1 && unicode.IsLower([]rune(seq)[0]) {
+		// Letters match both cases, show them like the old buttons did
+		return strings.ToUpper(seq)
+	}
+	return seq
+}
+
+// keymap resolves the bindings of every action from the config: the
+// defaults (plus the vim extras with "keymap": "vim"), replaced per action
+// by the keybindings section. It returns key sequence -> action id, the
+// sequences of each action, and every conflict found.
+func (c *Config) keymap() (map[string]string, map[string][]string, []error) {
+	var errs []error
+	bound := map[string]string{}
+	byAction := map[string][]string{}
+
+	// Bind the user's keys first so they win over the defaults
+	var order []*Action
+	for _, a := range actions {
+		if _, ok := c.Keybindings[a.ID]; ok {
+			order = append(order, a)
+		}
+	}
+	for _, a := range actions {
+		if _, ok := c.Keybindings[a.ID]; !ok {
+			order = append(order, a)
+		}
+	}
+
+	for _, a := range order {
+		specs := a.Keys
+		if c.Keymap == "vim" {
+			specs = append(append([]string(nil), a.Keys...), a.VimKeys...)
+		}
+		if custom, ok := c.Keybindings[a.ID]; ok {
+			specs = custom
+		}
+
+		for _, spec := range specs {
+			seq, err := parseKeySpec(spec)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("keybindings.%s: %v", a.ID, err))
+				continue
+			}
+			if other, taken := bound[seq]; taken {
+				errs = append(errs, fmt.Errorf("keybindings.%s: %q is already bound to %s", a.ID, spec, other))
+				continue
+			}
+			// A chord can't share its start with a shorter binding
+			if other := chordConflict(bound, seq); other != "" {
+				errs = append(errs, fmt.Errorf("keybindings.%s: %q overlaps the binding of %s", a.ID, spec, other))
+				continue
+			}
+			bound[seq] = a.ID
+			byAction[a.ID] = append(byAction[a.ID], seq)
+		}
+	}
+	return bound, byAction, errs
+}
+
+// chordConflict returns the action whose binding is a prefix of seq or has
+// seq as a prefix
+func chordConflict(bound map[string]string, seq string) string {
+	for other, id := range bound {
+		if strings.HasPrefix(other, seq+" ") || strings.HasPrefix(seq, other+" ") {
+			return id
+		}
+	}
+	return ""
+}
+
+// validateKeybindings checks the keymap and the keybindings section
+func (c *Config) validateKeybindings() []error {
+	var errs []error
+	if c.Keymap != "default" && c.Keymap != "vim" {
+		errs = append(errs, fmt.Errorf("keymap: unknown keymap %q, use default or vim", c.Keymap))
+		c.Keymap = DefaultConfig().Keymap
+	}
+
+	var unknown []string
+	for id := range c.Keybindings {
+		if findAction(id) == nil {
+			unknown = append(unknown, id)
+		}
+	}
+	sort.Strings(unknown)
+	for _, id := range unknown {
+		errs = append(errs, fmt.Errorf("keybindings.%s: unknown action", id))
+		delete(c.Keybindings, id)
+	}
+
+	_, _, conflicts := c.keymap()
+	return append(errs, conflicts...)
+}
+
+// handleKey runs the action bound to a key, keeping track of started chords.
+// It returns false if the key is not bound.
+func (t *TUI) handleKey(event *tcell.EventKey) bool {
+	name := eventKeyName(event)
+	if name == "" {
+		t.pendingKeys = ""
+		return false
+	}
+	if t.pendingKeys != "" && time.Since(t.pendingAt) > chordTimeout {
+		t.pendingKeys = ""
+	}
+
+	candidates := []string{name}
+	if lower := strings.ToLower(name); lower != name && len([]rune(name)) == 1 {
+		// Letters are bound in lower case and match both cases unless
+		// the upper case letter is bound on its own
+		candidates = append(candidates, lower)
+	}
+
+	prefixes := []string{t.pendingKeys}
+	if t.pendingKeys != "" {
+		// A key that doesn't continue the chord starts over
+		prefixes = append(prefixes, "")
+	}
+	for _, prefix := range prefixes {
+		for _, key := range candidates {
+			seq := key
+			if prefix != "" {
+				seq = prefix + " " + key
+			}
+			if id, ok := t.bindings[seq]; ok {
+				t.pendingKeys = ""
+				t.runAction(findAction(id))
+				return true
+			}
+			if t.isChordPrefix(seq) {
+				t.pendingKeys = seq
+				t.pendingAt = time.Now()
+				return true
+			}
+		}
+	}
+	t.pendingKeys = ""
+	return false
+}
+
+// isChordPrefix reports whether seq starts a longer binding
+func (t *TUI) isChordPrefix(seq string) bool {
+	for bound := range t.bindings {
+		if strings.HasPrefix(bound, seq+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// runAction runs an action unless it is disabled right now
+func (t *TUI) runAction(a *Action) {
+	if a == nil || !a.enabled(t) {
+		return
+	}
+	a.Run(t)
+}
+
+// buttonActions returns the actions shown on the button row
+func (t *TUI) buttonActions() []*Action {
+	var buttons []*Action
+	for _, a := range actions {
+		if a.Button != "" {
+			buttons = append(buttons, a)
+		}
+	}
+	return buttons
+}
+
+// currentTextView returns the TextView of the current view mode
+func (t *TUI) currentTextView() *tview.TextView {
+	return t.projections[t.viewMode].view
+}
+
+// halfPage returns half the visible height of the current view
+func (t *TUI) halfPage() int {
+	_, _, _, height := t.currentTextView().GetInnerRect()
+	if height < 2 {
+		return 1
+	}
+	return height / 2
+}
+
+// showHelp lists every action with its keys
+func (t *TUI) showHelp() {
+	var builder strings.Builder
+	for _, a := range actions {
+		keys := t.actionKeys[a.ID]
+		shown := make([]string, len(keys))
+		for i, seq := range keys {
+			shown[i] = displayKey(seq)
+		}
+		keyText := strings.Join(shown, ", ")
+		if keyText == "" {
+			keyText = "-"
+		}
+		role := "text"
+		if !a.enabled(t) {
+			role = "muted"
+		}
+		builder.WriteString(fmt.Sprintf(t.theme.C("[label]%-16s[text] %s%s[text]\n"),
+			tview.Escape(keyText), t.theme.Tag(role), a.Label))
+	}
+	builder.WriteString(t.theme.C("\n[label]Ctrl+C          [text] Quit, even from a dialog\n"))
+	builder.WriteString(t.theme.C("[label]Left, Right     [text] Select a button when the buttons have focus\n"))
+	builder.WriteString(t.theme.C("[label]Enter           [text] Press the selected button\n"))
+	builder.WriteString(t.theme.C("\n[muted]Keymap: " + t.config.Keymap + ". Press Esc or ? to close.[text]"))
+
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(builder.String())
+	help.SetBorder(true).
+		SetBorderPadding(0, 0, 1, 1).
+		SetTitle(" Keyboard Shortcuts ").
+		SetTitleAlign(tview.AlignLeft)
+
+	closeHelp := func() {
+		t.modalOpen = false
+		t.app.SetRoot(t.rootPages, true)
+		t.app.SetFocus(t.getCurrentView())
+	}
+	help.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc || event.Key() == tcell.KeyEnter ||
+			event.Rune() == '?' || event.Rune() == 'q' {
+			closeHelp()
+			return nil
+		}
+		return event
+	})
+
+	height := len(actions) + 7
+	centered := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(help, height, 0, true).
+			AddItem(nil, 0, 1, false), 72, 0, true).
+		AddItem(nil, 0, 1, false)
+
+	t.modalOpen = true
+	t.app.SetRoot(centered, true)
+	t.app.SetFocus(help)
+}
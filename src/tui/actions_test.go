@@ -0,0 +1,137 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseKeySpec(t *testing.T) {
+	tests := []struct {
+		spec, want string
+		err        bool
+	}{
+		{"q", "q", false},
+		{"Q", "Q", false},
+		{"=", "=", false},
+		{" ", " ", false},
+		{"Space", " ", false},
+		{"Ctrl+D", "Ctrl+D", false},
+		{"ctrl+d", "Ctrl+D", false},
+		{"Ctrl-D", "Ctrl+D", false},
+		{"C-d", "Ctrl+D", false},
+		{"^D", "Ctrl+D", false},
+		{"Alt+x", "Alt+x", false},
+		{"Esc", "Esc", false},
+		{"escape", "Esc", false},
+		{"return", "Enter", false},
+		{"pgup", "PgUp", false},
+		{"g g", "g g", false},
+		{"  g   g ", "g g", false},
+		{"Ctrl+W j", "Ctrl+W j", false},
+		{"", "", true},
+		{"   ", "", true},
+		{"Hyper+x", "", true},
+		{"g nope", "", true},
+	}
+	for _, tt := range tests {
+		got, err := parseKeySpec(tt.spec)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("parseKeySpec(%q) = %q, %v, want %q, error %v", tt.spec, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestChordConflict(t *testing.T) {
+	bound := map[string]string{"g g": "top", "q": "quit", "Ctrl+W j": "down"}
+	tests := []struct {
+		seq, want string
+	}{
+		{"g", "top"},
+		{"g g g", "top"},
+		{"g t", ""},
+		{"q", ""},
+		{"q x", "quit"},
+		{"Ctrl+W", "down"},
+		{"Ctrl+W k", ""},
+		{"gg", ""},
+	}
+	for _, tt := range tests {
+		if got := chordConflict(bound, tt.seq); got != tt.want {
+			t.Errorf("chordConflict(%q) = %q, want %q", tt.seq, got, tt.want)
+		}
+	}
+}
+
+func TestKeymapConflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		keymap   string
+		bindings map[string]keyList
+		want     map[string][]string // action -> its sequences, for the actions checked
+		errs     []string            // substrings of the errors, in order
+	}{
+		{
+			name: "defaults",
+			want: map[string][]string{"quit": {"q", "Esc"}, "top": {"Home"}},
+		},
+		{
+			name:   "vim extras",
+			keymap: "vim",
+			want:   map[string][]string{"top": {"Home", "g g"}, "filter": {"f", "/"}},
+		},
+		{
+			name:     "the user's key wins over a default",
+			bindings: map[string]keyList{"help": {"q"}},
+			want:     map[string][]string{"help": {"q"}, "quit": {"Esc"}},
+			errs:     []string{`keybindings.quit: "q" is already bound to help`},
+		},
+		{
+			name:     "a default can't start a bound chord",
+			bindings: map[string]keyList{"top": {"q q"}},
+			want:     map[string][]string{"top": {"q q"}, "quit": {"Esc"}},
+			errs:     []string{`keybindings.quit: "q" overlaps the binding of top`},
+		},
+		{
+			name:     "a key can't start a bound chord",
+			keymap:   "vim",
+			bindings: map[string]keyList{"help": {"g"}},
+			want:     map[string][]string{"help": {"g"}, "top": {"Home"}},
+			errs:     []string{`keybindings.top: "g g" overlaps the binding of help`},
+		},
+		{
+			name:     "unknown key",
+			bindings: map[string]keyList{"help": {"Hyper+h", "?"}},
+			want:     map[string][]string{"help": {"?"}},
+			errs:     []string{`keybindings.help: unknown key "Hyper+h"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := DefaultConfig()
+			if tt.keymap != "" {
+				c.Keymap = tt.keymap
+			}
+			c.Keybindings = tt.bindings
+			bound, byAction, errs := c.keymap()
+			for id, want := range tt.want {
+				if got := byAction[id]; !reflect.DeepEqual(got, want) {
+					t.Errorf("%s is bound to %q, want %q", id, got, want)
+				}
+				for _, seq := range want {
+					if bound[seq] != id {
+						t.Errorf("%q runs %q, want %s", seq, bound[seq], id)
+					}
+				}
+			}
+			if len(errs) != len(tt.errs) {
+				t.Fatalf("errors %v, want %q", errs, tt.errs)
+			}
+			for i, err := range errs {
+				if !strings.Contains(err.Error(), tt.errs[i]) {
+					t.Errorf("error %q, want %q", err, tt.errs[i])
+				}
+			}
+		})
+	}
+}
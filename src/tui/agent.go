@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// startAgent launches the documentor as a child process. Its stdout and
+// stderr become the message stream, so the TUI knows the agent's PID
+// without a lock file. Answers go to its stdin unless replies names a
+// file for them.
+func startAgent(args []string, replies string) (*exec.Cmd, io.Reader, io.Writer, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = w
+	cmd.Stderr = w
+	cmd.Env = os.Environ()
+	var stdin io.Writer
+	if replies == "" {
+		if stdin, err = cmd.StdinPipe(); err != nil {
+			r.Close()
+			w.Close()
+			return nil, nil, nil, err
+		}
+		replies = "stdin"
+	}
+	cmd.Env = append(cmd.Env, repliesEnv+"="+replies)
+	if err := cmd.Start(); err != nil {
+		r.Close()
+		w.Close()
+		return nil, nil, nil, err
+	}
+	// Only the child keeps the write end open, so the reader sees EOF
+	// once the agent and everything it spawned are gone
+	w.Close()
+	return cmd, r, stdin, nil
+}
+
+// waitAgent reports how the launched agent ended
+func (t *TUI) waitAgent() {
+	if t.agentCmd == nil {
+		return
+	}
+	err := t.agentCmd.Wait()
+	status := t.agentCmd.ProcessState.ExitCode()
+	if status < 0 {
+		// Killed by a signal
+		status = 1
+	}
+	t.agentStatus.Store(int64(status))
+	timestamp := time.Now().Format("15:04:05")
+	t.app.QueueUpdateDraw(func() {
+		t.addLog("info", fmt.Sprintf("Agent exited with status %d", t.agentCmd.ProcessState.ExitCode()), timestamp)
+		t.agentExited(err)
+	})
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// ApprovalRequest asks to write a file before the agent writes it
+type ApprovalRequest struct {
+	Type      string `json:"type"` // "approval_request"
+	RequestID string `json:"requestId"`
+	Tool      string `json:"tool"`    // Write, Edit, ...
+	Path      string `json:"path"`    // file to write
+	Content   string `json:"content"` // proposed content of the whole file
+	Context   string `json:"context"` // why, shown above the diff
+}
+
+// ApprovalResponse answers an approval_request
+type ApprovalResponse struct {
+	Type      string `json:"type"` // "approval_response"
+	RequestID string `json:"requestId"`
+	Decision  string `json:"decision"`          // approve, reject or edit
+	Content   string `json:"content,omitempty"` // the content to write instead, for edit
+	Auto      bool   `json:"auto,omitempty"`    // approved by an "approve all in directory" answer
+}
+
+// approvalGate is what waits for an answer, and the directories whose
+// writes are approved without asking
+type approvalGate struct {
+	queue []*ApprovalRequest
+	dirs  []string // project relative, "." for the whole project
+	open  bool     // the approval page is showing
+}
+
+// noteApproval queues an approval request, answering it right away if its
+// directory is approved already
+func (t *TUI) noteApproval(req *ApprovalRequest) {
+	if path := t.projectFile(req.Path); path != "" {
+		if _, seen := t.changes.base[path]; !seen {
+			// Nothing is written before the answer, a good time to snapshot
+			t.changes.base[path] = t.readSnapshot(path)
+		}
+	}
+	if t.approvedDir(req.Path) {
+		t.answerApproval(req, ApprovalResponse{Decision: "approve", Auto: true})
+		return
+	}
+	t.approvals.queue = append(t.approvals.queue, req)
+	t.setState(StateAwaitingInput, "approval requested for "+req.Path)
+	switch {
+	case t.approvals.open:
+		t.updateApproval()
+	case t.modalOpen:
+		t.showToast("Write approval waiting, press " + t.keyHint("approvals"))
+	default:
+		t.showApprovals()
+	}
+}
+
+// approvedDir reports whether writes to a file are approved by directory
+func (t *TUI) approvedDir(file string) bool {
+	file = t.projectFile(file)
+	if file == "" {
+		return false
+	}
+	for _, dir := range t.approvals.dirs {
+		if dir == "." || strings.HasPrefix(file, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// answerApproval sends the answer and logs it
+func (t *TUI) answerApproval(req *ApprovalRequest, resp ApprovalResponse) {
+	resp.Type = "approval_response"
+	resp.RequestID = req.RequestID
+	sent := t.reply(resp)
+
+	level, verb := "success", "Approved"
+	switch resp.Decision {
+	case "reject":
+		level, verb = "warning", "Rejected"
+	case "edit":
+		verb = "Approved with edits"
+	}
+	text := fmt.Sprintf("%s %s %s", verb, req.Tool, req.Path)
+	if resp.Auto {
+		text += " (directory approved)"
+	}
+	if !sent {
+		level = "error"
+		text += ", but there is no return channel to tell the agent"
+	}
+	t.addLog(level, text, time.Now().Format("15:04:05"))
+}
+
+// approvals is the approval page while it is open
+type approvals struct {
+	*diffPane
+	header *tview.TextView
+	page   *tview.Flex
+}
+
+// showApprovals opens the page of the oldest waiting request
+func (t *TUI) showApprovals() {
+	if len(t.approvals.queue) == 0 {
+		t.showToast("No write approvals waiting")
+		return
+	}
+	th := t.theme
+	a := &approvals{
+		diffPane: newDiffPane(t.config.Diff == "side-by-side"),
+		header: tview.NewTextView().
+			SetDynamicColors(true).
+			SetWrap(true),
+	}
+	a.render = func() string {
+		if len(t.approvals.queue) == 0 {
+			return ""
+		}
+		req := t.approvals.queue[0]
+		proposed := snapshot{content: req.Content, exists: true}
+		return t.renderChange(t.readSnapshot(req.Path), proposed, "", a.diffPane)
+	}
+	a.header.SetBorder(true).
+		SetBorderPadding(0, 0, 1, 1).
+		SetTitle(" \uf023 write approval ").
+		SetTitleAlign(tview.AlignLeft)
+
+	footer := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(th.C("[label]A[text] approve   [label]R[text] reject   [label]D[text] approve all in the directory   [label]E[text] edit   [label]S[text] unified/side by side   [label]Esc[text] later"))
+	footer.SetBorder(true).
+		SetBorderPadding(0, 0, 1, 1)
+
+	a.page = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(a.header, 5, 0, false).
+		AddItem(a.view, 0, 1, true).
+		AddItem(footer, 3, 0, false)
+
+	a.page.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		req := t.approvals.queue[0]
+		switch {
+		case event.Key() == tcell.KeyEsc:
+			// The request keeps waiting, the agent with it
+			t.closeApprovals()
+			t.showToast(fmt.Sprintf("%d write approval(s) waiting, press %s", len(t.approvals.queue), t.keyHint("approvals")))
+			return nil
+		case event.Rune() == 'a' || event.Rune() == 'A':
+			t.decideApproval(ApprovalResponse{Decision: "approve"})
+			return nil
+		case event.Rune() == 'r' || event.Rune() == 'R':
+			t.decideApproval(ApprovalResponse{Decision: "reject"})
+			return nil
+		case event.Rune() == 'd' || event.Rune() == 'D':
+			file := t.projectFile(req.Path)
+			if file == "" {
+				// path.Dir would make it ".", the whole project
+				t.showToast("The request names no file: approve or reject it on its own")
+				return nil
+			}
+			t.approvals.dirs = append(t.approvals.dirs, path.Dir(file))
+			t.approvals.queue = t.approvals.queue[1:]
+			t.answerApproval(req, ApprovalResponse{Decision: "approve"})
+			t.approveWaiting()
+			return nil
+		case event.Rune() == 'e' || event.Rune() == 'E':
+			t.editApproval(req)
+			return nil
+		case event.Rune() == 's' || event.Rune() == 'S':
+			a.sideBySide = !a.sideBySide
+			t.updateApproval()
+			return nil
+		}
+		return event
+	})
+
+	t.approvalPage = a
+	t.approvals.open = true
+	t.updateApproval()
+	t.modalOpen = true
+	t.app.SetRoot(a.page, true)
+	t.app.SetFocus(a.view)
+}
+
+// updateApproval shows the oldest waiting request
+func (t *TUI) updateApproval() {
+	a := t.approvalPage
+	if !t.approvals.open || len(t.approvals.queue) == 0 {
+		return
+	}
+	th := t.theme
+	req := t.approvals.queue[0]
+	text := fmt.Sprintf(th.C("[label]%s[text] %s"), tview.Escape(req.Tool), tview.Escape(req.Path))
+	if waiting := len(t.approvals.queue) - 1; waiting > 0 {
+		text += fmt.Sprintf(th.C("   [muted]%d more waiting[text]"), waiting)
+	}
+	if req.Context != "" {
+		text += "\n" + tview.Escape(req.Context)
+	}
+	if t.replies == nil {
+		text += th.C("\n[warning]No return channel: the agent will not get the answer. Launch it with documentor-tui -- … or pass -replies.[text]")
+	}
+	a.header.SetText(text)
+	a.view.SetTitle(fmt.Sprintf(" %s %s%s, %s%s ",
+		tview.Escape(path.Base(req.Path)), th.Tag("muted"), "file now → proposed", a.mode(), th.Tag("text")))
+	a.refresh()
+}
+
+// decideApproval answers the oldest waiting request and moves on to the
+// next one
+func (t *TUI) decideApproval(resp ApprovalResponse) {
+	req := t.approvals.queue[0]
+	t.approvals.queue = t.approvals.queue[1:]
+	t.answerApproval(req, resp)
+	t.nextApproval()
+}
+
+// approveWaiting answers the waiting requests a new directory approval covers
+func (t *TUI) approveWaiting() {
+	waiting := t.approvals.queue
+	t.approvals.queue = nil
+	for _, req := range waiting {
+		if t.approvedDir(req.Path) {
+			t.answerApproval(req, ApprovalResponse{Decision: "approve", Auto: true})
+		} else {
+			t.approvals.queue = append(t.approvals.queue, req)
+		}
+	}
+	t.nextApproval()
+}
+
+// nextApproval shows the next waiting request, or closes the page and lets
+// the run go on once all are answered
+func (t *TUI) nextApproval() {
+	if len(t.approvals.queue) > 0 {
+		t.updateApproval()
+		return
+	}
+	t.closeApprovals()
+	if t.state == StateAwaitingInput {
+		t.setState(StateWorking, "approvals answered")
+	}
+}
+
+// closeApprovals goes back to the logs
+func (t *TUI) closeApprovals() {
+	if !t.approvals.open {
+		return
+	}
+	t.approvals.open = false
+	t.modalOpen = false
+	t.app.SetRoot(t.rootPages, true)
+	t.app.SetFocus(t.getCurrentView())
+}
+
+// editApproval opens the proposed content in an editor and approves what
+// was saved, if it differs
+func (t *TUI) editApproval(req *ApprovalRequest) {
+	edited, err := t.editText(req.Content, "documentor-approve-*"+filepath.Ext(req.Path))
+	if err != nil {
+		t.showToast(err.Error())
+		return
+	}
+	if edited == req.Content {
+		t.decideApproval(ApprovalResponse{Decision: "approve"})
+		return
+	}
+	t.decideApproval(ApprovalResponse{Decision: "edit", Content: edited})
+}
+
+// editText lets the user edit text in $VISUAL or $EDITOR (default vi),
+// suspending the TUI meanwhile. pattern names the temp file, as for
+// os.CreateTemp, so the editor can tell the file type.
+func (t *TUI) editText(text, pattern string) (string, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", fmt.Errorf("cannot edit: %v", err)
+	}
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(text)
+	f.Close()
+	if err != nil {
+		return "", fmt.Errorf("cannot edit: %v", err)
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	// Standard input may be the agent's message stream, the editor needs
+	// the terminal itself
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return "", fmt.Errorf("cannot edit without a terminal: %v", err)
+	}
+	defer tty.Close()
+	args := append(strings.Fields(editor), f.Name())
+	t.app.Suspend(func() {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = tty, tty, tty
+		err = cmd.Run()
+	})
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %v", args[0], err)
+	}
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", fmt.Errorf("cannot read the edited file: %v", err)
+	}
+	return string(data), nil
+}
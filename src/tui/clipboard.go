@@ -0,0 +1,239 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// maxOSC52 is the largest base64 payload sent with OSC 52; terminals drop
+// longer ones silently, so bigger copies go to a file instead
+const maxOSC52 = 100000
+
+// viewEntries returns the events currently shown in a view, oldest first
+func (t *TUI) viewEntries(mode string) []Event {
+	p := t.projections[mode]
+	var entries []Event
+	for _, e := range t.events.All() {
+		if e.Seq >= p.firstSeq && t.visible(p, e) {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// startSelection enters line selection on the highlighted entry, or on the
+// newest one
+func (t *TUI) startSelection() {
+	entries := t.viewEntries(t.viewMode)
+	if len(entries) == 0 {
+		t.showToast("Nothing to select")
+		return
+	}
+	t.selecting = true
+	t.selAnchor = 0
+	t.selCursor = entries[len(entries)-1].Seq
+	if seqs := t.highlightedSeqs(); len(seqs) > 0 {
+		t.selCursor = seqs[len(seqs)-1]
+	}
+	t.showSelection()
+}
+
+// stopSelection leaves line selection and removes the highlight
+func (t *TUI) stopSelection() {
+	t.selecting = false
+	t.selAnchor = 0
+	t.currentTextView().Highlight()
+	t.updateFooter()
+}
+
+// handleSelectionKey moves the cursor, marks a range, copies or leaves
+// line selection. It reports whether the key was used.
+func (t *TUI) handleSelectionKey(event *tcell.EventKey) bool {
+	entries := t.viewEntries(t.viewMode)
+	if len(entries) == 0 {
+		t.stopSelection()
+		return false
+	}
+	cursor := len(entries) - 1
+	for i, e := range entries {
+		if e.Seq == t.selCursor {
+			cursor = i
+		}
+	}
+
+	move := 0
+	switch event.Key() {
+	case tcell.KeyUp:
+		move = -1
+	case tcell.KeyDown:
+		move = 1
+	case tcell.KeyPgUp:
+		move = -10
+	case tcell.KeyPgDn:
+		move = 10
+	case tcell.KeyHome:
+		move = -len(entries)
+	case tcell.KeyEnd:
+		move = len(entries)
+	case tcell.KeyEsc:
+		t.stopSelection()
+		return true
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case 'k':
+			move = -1
+		case 'j':
+			move = 1
+		case 'g':
+			move = -len(entries)
+		case 'G':
+			move = len(entries)
+		case ' ':
+			// Start a range here, or drop the one started
+			if t.selAnchor == 0 {
+				t.selAnchor = t.selCursor
+			} else {
+				t.selAnchor = 0
+			}
+			t.showSelection()
+			return true
+		case 'y', 'Y':
+			t.copyEntries(t.selectedEntries(entries))
+			t.stopSelection()
+			return true
+		case 'v', 'q':
+			t.stopSelection()
+			return true
+		}
+	}
+	if move == 0 {
+		return false
+	}
+	cursor = min(max(cursor+move, 0), len(entries)-1)
+	t.selCursor = entries[cursor].Seq
+	t.showSelection()
+	return true
+}
+
+// selectedEntries returns the entries between the anchor and the cursor
+func (t *TUI) selectedEntries(entries []Event) []Event {
+	from, to := t.selCursor, t.selCursor
+	if t.selAnchor != 0 {
+		from, to = min(t.selAnchor, t.selCursor), max(t.selAnchor, t.selCursor)
+	}
+	var selected []Event
+	for _, e := range entries {
+		if e.Seq >= from && e.Seq <= to {
+			selected = append(selected, e)
+		}
+	}
+	return selected
+}
+
+// showSelection highlights the selected entries and keeps the cursor in view
+func (t *TUI) showSelection() {
+	view := t.currentTextView()
+	var regions []string
+	for _, e := range t.selectedEntries(t.viewEntries(t.viewMode)) {
+		regions = append(regions, eventRegion(e.Seq))
+	}
+	view.Highlight(regions...)
+	if len(regions) > 0 {
+		// Scroll to the cursor end of the range
+		view.Highlight(eventRegion(t.selCursor))
+		view.ScrollToHighlight()
+		view.Highlight(regions...)
+	}
+	t.updateFooter()
+}
+
+// highlightedSeqs returns the entries highlighted in the current view, e.g.
+// by a mouse click
+func (t *TUI) highlightedSeqs() []int {
+	var seqs []int
+	for _, id := range t.currentTextView().GetHighlights() {
+		var seq int
+		if _, err := fmt.Sscanf(id, "e%d", &seq); err == nil {
+			seqs = append(seqs, seq)
+		}
+	}
+	return seqs
+}
+
+// copyHighlighted copies the entries highlighted outside of line selection
+func (t *TUI) copyHighlighted() {
+	seqs := t.highlightedSeqs()
+	if len(seqs) == 0 {
+		t.showToast("Click a line or press " + t.keyHint("select") + " to select one first")
+		return
+	}
+	var entries []Event
+	for _, e := range t.viewEntries(t.viewMode) {
+		for _, seq := range seqs {
+			if e.Seq == seq {
+				entries = append(entries, e)
+			}
+		}
+	}
+	t.copyEntries(entries)
+}
+
+// copyEntries copies entries as plain text, one per line
+func (t *TUI) copyEntries(entries []Event) {
+	if len(entries) == 0 {
+		return
+	}
+	lines := make([]string, len(entries))
+	for i, e := range entries {
+		lines[i] = e.PlainText()
+	}
+	what := "1 line"
+	if len(lines) > 1 {
+		what = fmt.Sprintf("%d lines", len(lines))
+	}
+	t.copyText(strings.Join(lines, "\n"), what)
+}
+
+// copyCurrentFile copies the path of the file being processed
+func (t *TUI) copyCurrentFile() {
+	if t.files.Current == "" {
+		t.showToast("No file is being processed")
+		return
+	}
+	t.copyText(t.files.Current, t.files.Current)
+}
+
+// copyText puts text on the system clipboard with OSC 52, which works over
+// SSH and, with passthrough, in tmux. Without a terminal, with the file
+// setting or for very long text it writes a temp file instead.
+func (t *TUI) copyText(text, what string) {
+	payload := base64.StdEncoding.EncodeToString([]byte(text))
+	if t.config.Clipboard == "osc52" && t.screen != nil && len(payload) <= maxOSC52 {
+		seq := "\x1b]52;c;" + payload + "\x07"
+		if os.Getenv("TMUX") != "" {
+			// tmux takes it itself with set-clipboard on, or passes it on
+			// with allow-passthrough on
+			t.writeTerminal(seq)
+		}
+		t.writeTerminal(passthrough(seq))
+		t.showToast("Copied " + what)
+		return
+	}
+
+	f, err := os.CreateTemp("", "documentor-copy-*.txt")
+	if err == nil {
+		_, err = f.WriteString(text + "\n")
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		t.showToast(fmt.Sprintf("Copy failed: %v", err))
+		return
+	}
+	t.showToast(fmt.Sprintf("Wrote %s to %s", what, f.Name()))
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Config holds every user-tunable setting of the TUI. It is loaded from
+// ~/.config/documentor/tui.json, then .documentor-tui.json in the working
+// directory, then command line flags, each overriding the previous. The
+// working directory, not the documented project: the config is read before
+// the agent names the project.
+type Config struct {
+	Layout        LayoutConfig           `json:"layout"`
+	Theme         string                 `json:"theme"`       // dark, light, high-contrast, solarized or a user theme
+	Themes        map[string]ThemeConfig `json:"themes"`      // user-defined themes
+	Colors        map[string]string      `json:"colors"`      // role overrides on top of the theme
+	Keymap        string                 `json:"keymap"`      // default or vim
+	Keybindings   map[string]keyList     `json:"keybindings"` // action -> keys, replacing its defaults
+	Scrollback    ScrollbackConfig       `json:"scrollback"`
+	ExportDir     string                 `json:"exportDir"` // empty = project directory
+	Notifications NotificationConfig     `json:"notifications"`
+	Monitor       MonitorConfig          `json:"monitor"`
+	Mouse         bool                   `json:"mouse"`      // clicks and wheel; false leaves selection to the terminal
+	Clipboard     string                 `json:"clipboard"`  // osc52, or file to always copy to a temp file
+	Diff          string                 `json:"diff"`       // unified or side-by-side, how the diff page opens
+	TickMillis    int                    `json:"tickMillis"` // spinner and status refresh
+	Spinner       []string               `json:"spinner"`
+}
+
+// LayoutConfig sets the fixed panel sizes
+type LayoutConfig struct {
+	InfoHeight    int `json:"infoHeight"`    // info + status panels, borders included
+	ButtonsHeight int `json:"buttonsHeight"` // button row
+	FooterHeight  int `json:"footerHeight"`
+	InfoWidth     int `json:"infoWidth"`  // relative width of the info panel
+	StatsWidth    int `json:"statsWidth"` // relative width of the status panel
+}
+
+// NotificationConfig sets how notices are shown
+type NotificationConfig struct {
+	ToastSeconds int                `json:"toastSeconds"`
+	WhenFocused  bool               `json:"whenFocused"` // also notify while the terminal has focus
+	OSC          string             `json:"osc"`         // desktop notification sequence: auto, 9 or 777
+	Command      []string           `json:"command"`     // e.g. ["notify-send", "{title}", "{body}"]
+	Events       NotificationEvents `json:"events"`
+}
+
+// NotificationEvents sets what each kind of event triggers
+type NotificationEvents struct {
+	Completed EventNotification `json:"completed"`
+	Failed    EventNotification `json:"failed"` // also input closing without a result
+	Stalled   EventNotification `json:"stalled"`
+	Input     EventNotification `json:"input"` // a password request
+}
+
+// EventNotification turns the notification channels of one event on or off
+type EventNotification struct {
+	Bell    bool `json:"bell"`
+	Desktop bool `json:"desktop"` // OSC 9/777
+	Command bool `json:"command"` // only if notifications.command is set
+}
+
+// get returns the settings of an event by name
+func (e NotificationEvents) get(event string) EventNotification {
+	switch event {
+	case "completed":
+		return e.Completed
+	case "failed":
+		return e.Failed
+	case "stalled":
+		return e.Stalled
+	case "input":
+		return e.Input
+	}
+	return EventNotification{}
+}
+
+// MonitorConfig tunes the agent process monitor
+type MonitorConfig struct {
+	IdleSeconds    int `json:"idleSeconds"`    // highlight processes without CPU use for this long
+	StallSeconds   int `json:"stallSeconds"`   // a working run without messages for this long is stalled
+	HistoryMinutes int `json:"historyMinutes"` // span of the trend sparklines
+}
+
+// DefaultConfig returns the settings used when no config file says otherwise
+func DefaultConfig() *Config {
+	return &Config{
+		Layout: LayoutConfig{
+			InfoHeight:    6,
+			ButtonsHeight: 3,
+			FooterHeight:  3,
+			InfoWidth:     3,
+			StatsWidth:    1,
+		},
+		Theme:       "dark",
+		Keymap:      "default",
+		Keybindings: map[string]keyList{},
+		Scrollback:  DefaultScrollback,
+		Notifications: NotificationConfig{
+			ToastSeconds: 5,
+			OSC:          "auto",
+			Events: NotificationEvents{
+				Completed: EventNotification{Bell: true, Desktop: true, Command: true},
+				Failed:    EventNotification{Bell: true, Desktop: true, Command: true},
+				Stalled:   EventNotification{Bell: true, Desktop: true, Command: true},
+				Input:     EventNotification{Bell: true, Desktop: true, Command: true},
+			},
+		},
+		Monitor: MonitorConfig{
+			IdleSeconds:    120,
+			StallSeconds:   60,
+			HistoryMinutes: 10,
+		},
+		Mouse:      true,
+		Clipboard:  "osc52",
+		Diff:       "unified",
+		TickMillis: 100,
+		Spinner:    []string{"◐", "◓", "◑", "◒"},
+	}
+}
+
+// userConfigPath returns ~/.config/documentor/tui.json, honoring XDG_CONFIG_HOME
+func userConfigPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "documentor", "tui.json")
+}
+
+// workdirConfigPath returns the override in the working directory
+func workdirConfigPath() string {
+	return ".documentor-tui.json"
+}
+
+// LoadConfig reads the user config (or the one given with --config) and the
+// working directory override on top of the defaults. Missing files are skipped; the
+// returned sources list the files that were applied. Call Validate once any
+// command line overrides have been applied.
+func LoadConfig(path string) (cfg *Config, sources []string, errs []error) {
+	cfg = DefaultConfig()
+
+	explicit := path != ""
+	if !explicit {
+		path = userConfigPath()
+	}
+	for i, p := range []string{path, workdirConfigPath()} {
+		if p == "" {
+			continue
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			if !os.IsNotExist(err) || (i == 0 && explicit) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		// Decode onto a copy so a broken file changes nothing
+		next := cfg.clone()
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&next); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", p, err))
+			continue
+		}
+		cfg = next
+		sources = append(sources, p)
+	}
+	return cfg, sources, errs
+}
+
+// Validate checks every setting and resets invalid ones to their defaults
+func (c *Config) Validate() []error {
+	var errs []error
+	def := DefaultConfig()
+
+	positive := func(name string, value *int, fallback int) {
+		if *value < 1 {
+			errs = append(errs, fmt.Errorf("%s must be at least 1, got %d", name, *value))
+			*value = fallback
+		}
+	}
+	positive("layout.infoHeight", &c.Layout.InfoHeight, def.Layout.InfoHeight)
+	positive("layout.buttonsHeight", &c.Layout.ButtonsHeight, def.Layout.ButtonsHeight)
+	positive("layout.footerHeight", &c.Layout.FooterHeight, def.Layout.FooterHeight)
+	positive("layout.infoWidth", &c.Layout.InfoWidth, def.Layout.InfoWidth)
+	positive("layout.statsWidth", &c.Layout.StatsWidth, def.Layout.StatsWidth)
+	positive("scrollback.lines", &c.Scrollback.Lines, def.Scrollback.Lines)
+	positive("scrollback.history", &c.Scrollback.History, def.Scrollback.History)
+	positive("monitor.idleSeconds", &c.Monitor.IdleSeconds, def.Monitor.IdleSeconds)
+	positive("monitor.stallSeconds", &c.Monitor.StallSeconds, def.Monitor.StallSeconds)
+	positive("monitor.historyMinutes", &c.Monitor.HistoryMinutes, def.Monitor.HistoryMinutes)
+
+	if c.TickMillis < 10 {
+		errs = append(errs, fmt.Errorf("tickMillis must be at least 10, got %d", c.TickMillis))
+		c.TickMillis = def.TickMillis
+	}
+	if len(c.Spinner) == 0 {
+		errs = append(errs, fmt.Errorf("spinner must have at least one frame"))
+		c.Spinner = def.Spinner
+	}
+	if c.Notifications.ToastSeconds < 0 {
+		errs = append(errs, fmt.Errorf("notifications.toastSeconds must not be negative"))
+		c.Notifications.ToastSeconds = def.Notifications.ToastSeconds
+	}
+	if c.Clipboard != "osc52" && c.Clipboard != "file" {
+		errs = append(errs, fmt.Errorf("clipboard must be osc52 or file, got %q", c.Clipboard))
+		c.Clipboard = def.Clipboard
+	}
+	if c.Diff != "unified" && c.Diff != "side-by-side" {
+		errs = append(errs, fmt.Errorf("diff must be unified or side-by-side, got %q", c.Diff))
+		c.Diff = def.Diff
+	}
+	if !containsString([]string{"auto", "9", "777"}, c.Notifications.OSC) {
+		errs = append(errs, fmt.Errorf("notifications.osc must be auto, 9 or 777, got %q", c.Notifications.OSC))
+		c.Notifications.OSC = def.Notifications.OSC
+	}
+	if len(c.Notifications.Command) > 0 && c.Notifications.Command[0] == "" {
+		errs = append(errs, fmt.Errorf("notifications.command must start with a program"))
+		c.Notifications.Command = nil
+	}
+
+	errs = append(errs, c.validateTheme()...)
+
+	if c.Keybindings == nil {
+		c.Keybindings = map[string]keyList{}
+	}
+	errs = append(errs, c.validateKeybindings()...)
+
+	return errs
+}
+
+// clone returns a deep copy of the config
+func (c *Config) clone() *Config {
+	next := *c
+	next.Keybindings = make(map[string]keyList, len(c.Keybindings))
+	for action, keys := range c.Keybindings {
+		next.Keybindings[action] = append(keyList(nil), keys...)
+	}
+	next.Colors = make(map[string]string, len(c.Colors))
+	for role, spec := range c.Colors {
+		next.Colors[role] = spec
+	}
+	next.Themes = make(map[string]ThemeConfig, len(c.Themes))
+	for name, theme := range c.Themes {
+		colors := make(map[string]string, len(theme.Colors))
+		for role, spec := range theme.Colors {
+			colors[role] = spec
+		}
+		theme.Colors = colors
+		next.Themes[name] = theme
+	}
+	next.Spinner = append([]string(nil), c.Spinner...)
+	next.Notifications.Command = append([]string(nil), c.Notifications.Command...)
+	return &next
+}
+
+// TickInterval returns the refresh interval of the spinner and status box
+func (c *Config) TickInterval() time.Duration {
+	return time.Duration(c.TickMillis) * time.Millisecond
+}
+
+// ToastDuration returns how long footer notices stay visible
+func (c *Config) ToastDuration() time.Duration {
+	return time.Duration(c.Notifications.ToastSeconds) * time.Second
+}
+
+// IdleDuration returns how long a process may go without CPU before it is highlighted
+func (m MonitorConfig) IdleDuration() time.Duration {
+	return time.Duration(m.IdleSeconds) * time.Second
+}
+
+// StallDuration returns how long a working run may go without messages
+func (m MonitorConfig) StallDuration() time.Duration {
+	return time.Duration(m.StallSeconds) * time.Second
+}
+
+// expandHome resolves a leading ~ in a path
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// dumpConfig prints the effective configuration for `documentor-tui config dump`
+func dumpConfig(cfg *Config, sources []string, errs []error) int {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Println(string(data))
+
+	if len(sources) == 0 {
+		fmt.Fprintln(os.Stderr, "# no config files found, showing defaults")
+	}
+	for _, source := range sources {
+		fmt.Fprintf(os.Stderr, "# loaded %s\n", source)
+	}
+	for _, err := range errs {
+		fmt.Fprintf(os.Stderr, "# error: %v\n", err)
+	}
+	if len(errs) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConfigValidate(t *testing.T) {
+	if errs := DefaultConfig().Validate(); len(errs) > 0 {
+		t.Fatalf("the default config is invalid: %v", errs)
+	}
+	tests := []struct {
+		name string
+		edit func(c *Config)
+	}{
+		{"info height", func(c *Config) { c.Layout.InfoHeight = 0 }},
+		{"footer height", func(c *Config) { c.Layout.FooterHeight = -3 }},
+		{"scrollback lines", func(c *Config) { c.Scrollback.Lines = 0 }},
+		{"scrollback history", func(c *Config) { c.Scrollback.History = -1 }},
+		{"tick", func(c *Config) { c.TickMillis = 5 }},
+		{"spinner", func(c *Config) { c.Spinner = nil }},
+		{"toast", func(c *Config) { c.Notifications.ToastSeconds = -1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := DefaultConfig()
+			tt.edit(c)
+			if errs := c.Validate(); len(errs) != 1 {
+				t.Errorf("Validate = %v, want one error", errs)
+			}
+			// The bad value falls back to the default
+			if !reflect.DeepEqual(c, DefaultConfig()) {
+				t.Errorf("Validate left %+v", c)
+			}
+		})
+	}
+}
@@ -0,0 +1,645 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// maxChanges is how many Write and Edit calls keep their content
+const maxChanges = 200
+
+// maxDiffCells bounds the line diff, whose table of 4 byte cells is built on
+// the UI goroutine at every render: above it changed blocks are shown as
+// removed and added whole
+const maxDiffCells = 1_000_000
+
+// diffContext is the number of unchanged lines shown around a change
+const diffContext = 3
+
+// maxSnapshotBytes is how much of a file a snapshot keeps; they are read as
+// tool messages come in, on the UI goroutine
+const maxSnapshotBytes = 256 << 10
+
+// ToolChange is the content a Write or Edit tool call replaced. Edit calls
+// carry the replaced snippet only, Write calls the whole file.
+type ToolChange struct {
+	Old     string `json:"old"`
+	New     string `json:"new"`
+	Existed *bool  `json:"existed,omitempty"` // the file was there before a Write, even if empty
+	Partial bool   `json:"partial,omitempty"` // an Edit: Old and New are snippets of the file
+}
+
+// existed tells whether the file was there before the call, and whether
+// that is known: an empty Old without Existed may be a new or an empty file
+func (c *ToolChange) existed() (existed, known bool) {
+	switch {
+	case c.Partial || c.Old != "":
+		return true, true
+	case c.Existed != nil:
+		return *c.Existed, true
+	}
+	return false, false
+}
+
+// snapshot is the content of a file at some point of the run
+type snapshot struct {
+	content    string
+	exists     bool
+	truncated  bool // the file was larger than maxSnapshotBytes
+	afterWrite bool // read once a write call had changed it: not the file before the run
+}
+
+// fileChange is one Write or Edit call
+type fileChange struct {
+	seq     int
+	time    time.Time
+	tool    string
+	path    string // project relative
+	before  snapshot
+	after   *snapshot // nil until the next call on the file, or if it is the last
+	partial bool
+}
+
+// changeSet is what the run changed: files as first seen, and every call
+// that wrote to one
+type changeSet struct {
+	base    map[string]snapshot // project relative path -> content when first seen
+	calls   []*fileChange
+	written []string // every file a call wrote, in order, kept when calls are dropped
+}
+
+func newChangeSet() *changeSet {
+	return &changeSet{base: map[string]snapshot{}}
+}
+
+// readSnapshot reads a file as it is now
+func (t *TUI) readSnapshot(path string) snapshot {
+	data, err := readHead(t.resolvePath(path), maxSnapshotBytes+1)
+	if err != nil {
+		return snapshot{}
+	}
+	if len(data) > maxSnapshotBytes {
+		return snapshot{content: string(data[:maxSnapshotBytes]), exists: true, truncated: true}
+	}
+	return snapshot{content: string(data), exists: true}
+}
+
+// noteChange snapshots the files tool calls touch the first time they show
+// up, and keeps the content of every Write and Edit call
+func (t *TUI) noteChange(msg Message) {
+	if msg.Type != "tool" || !fileTools.MatchString(msg.Tool) || !looksLikePath(msg.Content) {
+		return
+	}
+	path := t.projectFile(msg.Content)
+	if path == "" {
+		return
+	}
+	set := t.changes
+	// The file as it is now, read at most once and only if needed
+	var now *snapshot
+	read := func() snapshot {
+		if now == nil {
+			s := t.readSnapshot(path)
+			now = &s
+		}
+		return *now
+	}
+	if _, seen := set.base[path]; !seen {
+		existed, known := false, false
+		if msg.Change != nil && !msg.Change.Partial {
+			existed, known = msg.Change.existed()
+		}
+		if known {
+			// The file may already be written, its old content is more reliable
+			set.base[path] = snapshot{content: msg.Change.Old, exists: existed}
+		} else {
+			base := read()
+			// Write and Edit calls are reported once done
+			base.afterWrite = writeTools.MatchString(msg.Tool)
+			set.base[path] = base
+		}
+	}
+	if !writeTools.MatchString(msg.Tool) {
+		return
+	}
+	// Only writes reported by absolute path are reviewed: a bare name may
+	// mean a file elsewhere than the project
+	if filepath.IsAbs(msg.Content) && !containsString(set.written, path) {
+		set.written = append(set.written, path)
+	}
+
+	// The file as it is now is where the previous call on it ended
+	for i := len(set.calls) - 1; i >= 0; i-- {
+		if prev := set.calls[i]; prev.path == path {
+			if prev.after == nil {
+				after := read()
+				prev.after = &after
+			}
+			break
+		}
+	}
+
+	c := &fileChange{seq: t.events.LastSeq(), time: time.Now(), tool: msg.Tool, path: path}
+	if msg.Change != nil {
+		existed, _ := msg.Change.existed()
+		c.before = snapshot{content: msg.Change.Old, exists: existed}
+		c.after = &snapshot{content: msg.Change.New, exists: true}
+		c.partial = msg.Change.Partial
+	} else {
+		c.before = read()
+	}
+	set.calls = append(set.calls, c)
+	if len(set.calls) > maxChanges {
+		set.calls = set.calls[len(set.calls)-maxChanges:]
+	}
+}
+
+// diffOp is one line of a diff: ' ' unchanged, '-' removed, '+' added
+type diffOp struct {
+	kind       byte
+	text       string
+	oldN, newN int // line numbers, 0 where the line is not on that side
+}
+
+// splitLines splits text into lines, without a trailing empty one
+func splitLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines computes a line diff of a and b from their longest common
+// subsequence, after setting the common head and tail aside
+func diffLines(a, b []string) []diffOp {
+	head := 0
+	for head < len(a) && head < len(b) && a[head] == b[head] {
+		head++
+	}
+	tail := 0
+	for tail < len(a)-head && tail < len(b)-head && a[len(a)-1-tail] == b[len(b)-1-tail] {
+		tail++
+	}
+	midA, midB := a[head:len(a)-tail], b[head:len(b)-tail]
+
+	var ops []diffOp
+	oldN, newN := 0, 0
+	same := func(text string) {
+		oldN++
+		newN++
+		ops = append(ops, diffOp{' ', text, oldN, newN})
+	}
+	removed := func(text string) {
+		oldN++
+		ops = append(ops, diffOp{'-', text, oldN, 0})
+	}
+	added := func(text string) {
+		newN++
+		ops = append(ops, diffOp{'+', text, 0, newN})
+	}
+
+	for _, line := range a[:head] {
+		same(line)
+	}
+	if n, m := len(midA), len(midB); n*m > maxDiffCells {
+		for _, line := range midA {
+			removed(line)
+		}
+		for _, line := range midB {
+			added(line)
+		}
+	} else {
+		// lcs[i][j] is the length of the common subsequence of midA[i:] and midB[j:]
+		lcs := make([][]int32, n+1)
+		for i := range lcs {
+			lcs[i] = make([]int32, m+1)
+		}
+		for i := n - 1; i >= 0; i-- {
+			for j := m - 1; j >= 0; j-- {
+				if midA[i] == midB[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < n || j < m {
+			switch {
+			case i < n && j < m && midA[i] == midB[j]:
+				same(midA[i])
+				i++
+				j++
+			case i < n && (j == m || lcs[i+1][j] >= lcs[i][j+1]):
+				removed(midA[i])
+				i++
+			default:
+				added(midB[j])
+				j++
+			}
+		}
+	}
+	for _, line := range a[len(a)-tail:] {
+		same(line)
+	}
+	return ops
+}
+
+// diffHunk is a run of changed lines with their context
+type diffHunk struct {
+	ops []diffOp
+}
+
+// hunks groups the changes of a diff, with diffContext lines around each
+func hunks(ops []diffOp) []diffHunk {
+	var out []diffHunk
+	start, end := -1, -1
+	for i, op := range ops {
+		if op.kind == ' ' {
+			continue
+		}
+		from, to := max(0, i-diffContext), min(len(ops), i+diffContext+1)
+		if start >= 0 && from <= end {
+			end = to
+			continue
+		}
+		if start >= 0 {
+			out = append(out, diffHunk{ops[start:end]})
+		}
+		start, end = from, to
+	}
+	if start >= 0 {
+		out = append(out, diffHunk{ops[start:end]})
+	}
+	return out
+}
+
+// header is the @@ line of a hunk
+func (h diffHunk) header() string {
+	oldStart, newStart, oldCount, newCount := 0, 0, 0, 0
+	for _, op := range h.ops {
+		if op.oldN > 0 {
+			if oldStart == 0 {
+				oldStart = op.oldN
+			}
+			oldCount++
+		}
+		if op.newN > 0 {
+			if newStart == 0 {
+				newStart = op.newN
+			}
+			newCount++
+		}
+	}
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", oldStart, oldCount, newStart, newCount)
+}
+
+// diffStat counts the added and removed lines
+func diffStat(ops []diffOp) (added, removed int) {
+	for _, op := range ops {
+		switch op.kind {
+		case '+':
+			added++
+		case '-':
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// diffEntry is an entry of the diff list: a single call, or the whole run
+// for one file
+type diffEntry struct {
+	label string
+	path  string
+	call  *fileChange // nil for the cumulative diff of path
+}
+
+// diffPane shows a unified or side-by-side diff
+type diffPane struct {
+	view       *tview.TextView
+	sideBySide bool
+	width      int           // inner width the side-by-side diff was laid out for
+	render     func() string // renders the diff in the current mode and width
+}
+
+// newDiffPane creates the bordered view of a diff
+func newDiffPane(sideBySide bool) *diffPane {
+	p := &diffPane{
+		view:       tview.NewTextView().SetDynamicColors(true).SetScrollable(true).SetWrap(false),
+		sideBySide: sideBySide,
+	}
+	p.view.SetBorder(true).
+		SetBorderPadding(0, 0, 1, 1).
+		SetTitleAlign(tview.AlignLeft)
+	p.view.SetDrawFunc(func(screen tcell.Screen, x, y, width, height int) (int, int, int, int) {
+		// Called before the text is drawn: lay the side-by-side diff out
+		// again when the width changed
+		inner := width - 4
+		if p.sideBySide && inner != p.width && p.render != nil {
+			p.width = inner
+			row, col := p.view.GetScrollOffset()
+			p.view.SetText(p.render())
+			p.view.ScrollTo(row, col)
+		}
+		return x + 2, y + 1, width - 4, height - 2
+	})
+	return p
+}
+
+// refresh renders the diff again from the top
+func (p *diffPane) refresh() {
+	p.view.SetText(p.render())
+	p.view.ScrollToBeginning()
+}
+
+// mode names the diff mode for titles
+func (p *diffPane) mode() string {
+	if p.sideBySide {
+		return "side by side"
+	}
+	return "unified"
+}
+
+// diffViewer is the open diff page
+type diffViewer struct {
+	*diffPane
+	entries []diffEntry
+	list    *tview.List
+	page    *tview.Flex
+	current int
+}
+
+// diffEntries lists the cumulative diff of every changed file, then the
+// calls, newest first
+func (t *TUI) diffEntries() []diffEntry {
+	set := t.changes
+	changed := map[string]bool{}
+	for _, c := range set.calls {
+		changed[c.path] = true
+	}
+	paths := make([]string, 0, len(changed))
+	for path := range changed {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var entries []diffEntry
+	for _, path := range paths {
+		entries = append(entries, diffEntry{label: "\u03a3 " + path, path: path})
+	}
+	for i := len(set.calls) - 1; i >= 0; i-- {
+		c := set.calls[i]
+		entries = append(entries, diffEntry{
+			label: fmt.Sprintf("%s %s %s", c.time.Format("15:04:05"), c.tool, c.path),
+			path:  c.path,
+			call:  c,
+		})
+	}
+	return entries
+}
+
+// diffTarget picks the entry to open with: the call of the highlighted log
+// line, the file selected in the file tree, or the last call
+func (t *TUI) diffTarget(entries []diffEntry) int {
+	seqs := t.highlightedSeqs()
+	for i := len(seqs) - 1; i >= 0; i-- {
+		for n, entry := range entries {
+			if entry.call != nil && entry.call.seq == seqs[i] {
+				return n
+			}
+		}
+	}
+	if t.focusedWidget == "files" {
+		if node := t.filesTree.GetCurrentNode(); node != nil {
+			for n, entry := range entries {
+				if entry.call == nil && entry.path == node.GetReference() {
+					return n
+				}
+			}
+		}
+	}
+	for n, entry := range entries {
+		if entry.call != nil {
+			return n
+		}
+	}
+	return 0
+}
+
+// showDiffs opens the diff page on the selected Write or Edit call
+func (t *TUI) showDiffs() {
+	entries := t.diffEntries()
+	if len(entries) == 0 {
+		t.showToast("No Write or Edit calls yet")
+		return
+	}
+	th := t.theme
+	d := &diffViewer{
+		diffPane: newDiffPane(t.config.Diff == "side-by-side"),
+		entries:  entries,
+		list:     tview.NewList().ShowSecondaryText(false).SetHighlightFullLine(true),
+	}
+	d.render = func() string {
+		before, after, note := t.diffSides(d.entries[d.current])
+		return t.renderChange(before, after, note, d.diffPane)
+	}
+	d.list.SetBorder(true).
+		SetTitle(" changes ").
+		SetTitleAlign(tview.AlignLeft)
+	d.list.SetMainTextColor(th.Color("text")).
+		SetSelectedBackgroundColor(th.Color("accent"))
+	for _, entry := range entries {
+		d.list.AddItem(tview.Escape(entry.label), "", 0, nil)
+	}
+	d.list.SetChangedFunc(func(index int, _, _ string, _ rune) {
+		d.current = index
+		t.loadDiff(d)
+	})
+
+	footer := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(th.C("[label]Tab[text] list/diff   [label]S[text] unified/side by side   [label]R[text] reload   [label]Esc[text] back to the logs   [label]Q[text] quit"))
+	footer.SetBorder(true).
+		SetBorderPadding(0, 0, 1, 1)
+
+	listWidth := filesWidth
+	if t.layout != "full" {
+		listWidth = filesWidthCompact
+	}
+	d.page = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tview.NewFlex().
+			AddItem(d.list, listWidth, 0, false).
+			AddItem(d.view, 0, 1, true), 0, 1, true).
+		AddItem(footer, 3, 0, false)
+
+	closeDiffs := func() {
+		t.modalOpen = false
+		t.app.SetRoot(t.rootPages, true)
+		t.app.SetFocus(t.getCurrentView())
+	}
+	d.page.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc:
+			closeDiffs()
+			return nil
+		case event.Key() == tcell.KeyTab || event.Key() == tcell.KeyBacktab:
+			if d.list.HasFocus() {
+				t.app.SetFocus(d.view)
+			} else {
+				t.app.SetFocus(d.list)
+			}
+			return nil
+		case event.Rune() == 'q' || event.Rune() == 'Q':
+			t.app.Stop()
+			return nil
+		case event.Rune() == 's' || event.Rune() == 'S':
+			d.sideBySide = !d.sideBySide
+			t.loadDiff(d)
+			return nil
+		case event.Rune() == 'r' || event.Rune() == 'R':
+			t.loadDiff(d)
+			return nil
+		}
+		return event
+	})
+
+	d.current = t.diffTarget(entries)
+	d.list.SetCurrentItem(d.current)
+	t.loadDiff(d)
+	t.modalOpen = true
+	t.app.SetRoot(d.page, true)
+	t.app.SetFocus(d.view)
+}
+
+// loadDiff renders the selected entry
+func (t *TUI) loadDiff(d *diffViewer) {
+	th := t.theme
+	entry := d.entries[d.current]
+	kind := "whole run"
+	if entry.call != nil {
+		kind = entry.call.tool + " at " + entry.call.time.Format("15:04:05")
+	}
+	d.view.SetTitle(fmt.Sprintf(" \uf440 %s %s%s, %s%s ",
+		tview.Escape(entry.path), th.Tag("muted"), tview.Escape(kind), d.mode(), th.Tag("text")))
+	d.refresh()
+}
+
+// diffSides returns what the entry compares: the file as first seen or
+// before the call, and the file now or after the call
+func (t *TUI) diffSides(entry diffEntry) (before, after snapshot, note string) {
+	if entry.call == nil {
+		return t.changes.base[entry.path], t.readSnapshot(entry.path), ""
+	}
+	c := entry.call
+	if c.partial {
+		return c.before, *c.after, "The agent sent the replaced text only, line numbers are relative to it"
+	}
+	if c.after != nil {
+		return c.before, *c.after, ""
+	}
+	return c.before, t.readSnapshot(c.path), ""
+}
+
+// renderChange renders the change from before to after as the pane's
+// unified or side-by-side diff, with a note below the stats
+func (t *TUI) renderChange(before, after snapshot, note string, p *diffPane) string {
+	th := t.theme
+	ops := diffLines(splitLines(before.content), splitLines(after.content))
+	added, removed := diffStat(ops)
+
+	var out strings.Builder
+	switch {
+	case !before.exists && !after.exists:
+		out.WriteString(th.C("[muted]The file does not exist.[text]\n"))
+	case !before.exists:
+		out.WriteString(th.C("[success]New file[text]  "))
+	case !after.exists:
+		out.WriteString(th.C("[error]Deleted[text]  "))
+	}
+	fmt.Fprintf(&out, th.C("[success]+%d[text] [error]-%d[text]\n"), added, removed)
+	if before.truncated || after.truncated {
+		fmt.Fprintf(&out, th.C("[muted]Only the first %d KiB of the file are compared.[text]\n"), maxSnapshotBytes>>10)
+	}
+	if note != "" {
+		out.WriteString(th.C("[muted]" + note + "[text]\n"))
+	}
+	groups := hunks(ops)
+	if len(groups) == 0 {
+		out.WriteString(th.C("\n[muted]No changes.[text]\n"))
+		return out.String()
+	}
+	for _, h := range groups {
+		out.WriteString("\n" + th.Tag("accent") + h.header() + th.Tag("text") + "\n")
+		if p.sideBySide {
+			t.renderSideBySide(&out, h, p.width)
+		} else {
+			t.renderUnified(&out, h)
+		}
+	}
+	return out.String()
+}
+
+// diffRoles are the theme roles of the diff line kinds
+var diffRoles = map[byte]string{' ': "text", '-': "error", '+': "success"}
+
+// renderUnified writes a hunk with +/- markers
+func (t *TUI) renderUnified(out *strings.Builder, h diffHunk) {
+	th := t.theme
+	for _, op := range h.ops {
+		fmt.Fprintf(out, "%s%c %s%s\n", th.Tag(diffRoles[op.kind]), op.kind, tview.Escape(op.text), th.Tag("text"))
+	}
+}
+
+// renderSideBySide writes a hunk as two columns, old left and new right,
+// pairing the removed lines of a change with the added ones
+func (t *TUI) renderSideBySide(out *strings.Builder, h diffHunk, width int) {
+	th := t.theme
+	column := max((width-3)/2, 12)
+	cell := func(op *diffOp, n int) string {
+		if op == nil {
+			return strings.Repeat(" ", column)
+		}
+		text := padRight(fmt.Sprintf("%4d %s", n, op.text), column)
+		return th.Tag(diffRoles[op.kind]) + tview.Escape(text) + th.Tag("text")
+	}
+	separator := th.Tag("muted") + " │ " + th.Tag("text")
+
+	ops := h.ops
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			out.WriteString(cell(&ops[i], ops[i].oldN) + separator + cell(&ops[i], ops[i].newN) + "\n")
+			i++
+			continue
+		}
+		var removed, added []*diffOp
+		for ; i < len(ops) && ops[i].kind != ' '; i++ {
+			if ops[i].kind == '-' {
+				removed = append(removed, &ops[i])
+			} else {
+				added = append(added, &ops[i])
+			}
+		}
+		for n := 0; n < max(len(removed), len(added)); n++ {
+			left, right := "", ""
+			if n < len(removed) {
+				left = cell(removed[n], removed[n].oldN)
+			} else {
+				left = cell(nil, 0)
+			}
+			if n < len(added) {
+				right = cell(added[n], added[n].newN)
+			} else {
+				right = cell(nil, 0)
+			}
+			out.WriteString(left + separator + right + "\n")
+		}
+	}
+}
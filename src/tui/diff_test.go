@@ -0,0 +1,135 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// opLines writes a diff the way a unified diff would, one string per line
+func opLines(ops []diffOp) []string {
+	var lines []string
+	for _, op := range ops {
+		lines = append(lines, string(op.kind)+op.text)
+	}
+	return lines
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []string
+	}{
+		{"both empty", "", "", nil},
+		{"same", "a\nb\n", "a\nb\n", []string{" a", " b"}},
+		{"new file", "", "a\nb\n", []string{"+a", "+b"}},
+		{"deleted file", "a\nb\n", "", []string{"-a", "-b"}},
+		{"changed line", "a\nb\nc\n", "a\nx\nc\n", []string{" a", "-b", "+x", " c"}},
+		{"inserted line", "a\nc\n", "a\nb\nc\n", []string{" a", "+b", " c"}},
+		{"removed line", "a\nb\nc\n", "a\nc\n", []string{" a", "-b", " c"}},
+		{"appended", "a\n", "a\nb\n", []string{" a", "+b"}},
+		{"prepended", "b\n", "a\nb\n", []string{"+a", " b"}},
+		{"moved", "a\nb\nc\n", "b\nc\na\n", []string{"-a", " b", " c", "+a"}},
+		{"crlf", "a\r\nb\r\n", "a\nb\n", []string{" a", " b"}},
+		{"no trailing newline", "a\nb", "a\nb\n", []string{" a", " b"}},
+		{"common middle", "a\nx\nb\ny\n", "c\nx\nd\ny\n", []string{"-a", "+c", " x", "-b", "+d", " y"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := splitLines(tt.a), splitLines(tt.b)
+			ops := diffLines(a, b)
+			if got := opLines(ops); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("diffLines(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+			}
+			// Both sides come back whole, numbered from 1
+			var old, new []string
+			for _, op := range ops {
+				if op.kind != '+' {
+					old = append(old, op.text)
+					if op.oldN != len(old) {
+						t.Errorf("%q is old line %d, want %d", op.text, op.oldN, len(old))
+					}
+				}
+				if op.kind != '-' {
+					new = append(new, op.text)
+					if op.newN != len(new) {
+						t.Errorf("%q is new line %d, want %d", op.text, op.newN, len(new))
+					}
+				}
+			}
+			if !reflect.DeepEqual(old, a) || !reflect.DeepEqual(new, b) {
+				t.Errorf("the diff gives %q and %q back, want %q and %q", old, new, a, b)
+			}
+		})
+	}
+}
+
+func TestHunks(t *testing.T) {
+	lines := func(n int) string {
+		var b strings.Builder
+		for i := 1; i <= n; i++ {
+			b.WriteString(strings.Repeat("l", i) + "\n")
+		}
+		return b.String()
+	}
+	change := func(text string, line int, to string) string {
+		all := splitLines(text)
+		all[line-1] = to
+		return strings.Join(all, "\n") + "\n"
+	}
+	twenty := lines(20)
+
+	tests := []struct {
+		name string
+		a, b string
+		want []string
+	}{
+		{"no change", twenty, twenty, nil},
+		{"first line", twenty, change(twenty, 1, "x"), []string{"@@ -1,4 +1,4 @@"}},
+		{"middle", twenty, change(twenty, 10, "x"), []string{"@@ -7,7 +7,7 @@"}},
+		{"last line", twenty, change(twenty, 20, "x"), []string{"@@ -17,4 +17,4 @@"}},
+		{"close changes merge", twenty, change(change(twenty, 5, "x"), 11, "y"), []string{"@@ -2,13 +2,13 @@"}},
+		{"far changes split", twenty, change(change(twenty, 3, "x"), 12, "y"), []string{"@@ -1,6 +1,6 @@", "@@ -9,7 +9,7 @@"}},
+		{"appended", lines(5), lines(6), []string{"@@ -3,3 +3,4 @@"}},
+		{"new file", "", "a\nb\n", []string{"@@ -0,0 +1,2 @@"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, h := range hunks(diffLines(splitLines(tt.a), splitLines(tt.b))) {
+				got = append(got, h.header())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("hunks = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffStat(t *testing.T) {
+	added, removed := diffStat(diffLines(splitLines("a\nb\nc\n"), splitLines("a\nx\ny\n")))
+	if added != 2 || removed != 2 {
+		t.Errorf("diffStat = +%d -%d, want +2 -2", added, removed)
+	}
+}
+
+func TestToolChangeExisted(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		name           string
+		change         ToolChange
+		existed, known bool
+	}{
+		{"old content", ToolChange{Old: "a", New: "b"}, true, true},
+		{"edit", ToolChange{New: "b", Partial: true}, true, true},
+		{"empty file", ToolChange{New: "b", Existed: &yes}, true, true},
+		{"new file", ToolChange{New: "b", Existed: &no}, false, true},
+		{"not said", ToolChange{New: "b"}, false, false},
+	}
+	for _, tt := range tests {
+		if existed, known := tt.change.existed(); existed != tt.existed || known != tt.known {
+			t.Errorf("%s: existed() = %v, %v, want %v, %v", tt.name, existed, known, tt.existed, tt.known)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// Event is a typed entry in the event store. Every view is rebuilt from
+// these, so nothing is lost when a view is cleared or filtered.
+type Event struct {
+	Seq       int
+	Time      time.Time
+	Timestamp string // display timestamp, as sent by the agent
+	Type      string // log, tool, debug, raw, or a state message type
+	Level     string
+	Phase     int // phase that was active when the event arrived
+	PhaseName string
+	Tool      string
+	Content   string
+	Message   *Message // original message, nil for events raised by the TUI
+}
+
+// levelKey is the level used by log filters; tool calls count as "tool"
+func (e Event) levelKey() string {
+	if e.Type == "tool" {
+		return "tool"
+	}
+	return e.Level
+}
+
+// PlainText renders the event as a single untagged line
+func (e Event) PlainText() string {
+	switch e.Type {
+	case "tool":
+		return fmt.Sprintf("%s %s: %s", e.Timestamp, e.Tool, e.Content)
+	case "log":
+		return fmt.Sprintf("%s [%s] %s", e.Timestamp, e.Level, e.Content)
+	default:
+		return fmt.Sprintf("%s [%s] %s", e.Timestamp, e.Type, e.Content)
+	}
+}
+
+// EventStore keeps the events received during the run in arrival order.
+// It is a ring buffer: once full, the oldest event is evicted for every new one.
+type EventStore struct {
+	buf     []Event
+	start   int // index of the oldest event in buf
+	count   int
+	lastSeq int
+	evicted int // events dropped since the run started
+
+	// onEvict is called with every event pushed out of the buffer
+	onEvict func(e Event)
+}
+
+// NewEventStore creates a store holding at most capacity events
+func NewEventStore(capacity int) *EventStore {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &EventStore{buf: make([]Event, capacity)}
+}
+
+// Append assigns the next sequence number and stores the event
+func (s *EventStore) Append(e Event) Event {
+	s.lastSeq++
+	e.Seq = s.lastSeq
+
+	if s.count == len(s.buf) {
+		old := s.buf[s.start]
+		s.buf[s.start] = e
+		s.start = (s.start + 1) % len(s.buf)
+		s.evicted++
+		if s.onEvict != nil {
+			s.onEvict(old)
+		}
+		return e
+	}
+
+	s.buf[(s.start+s.count)%len(s.buf)] = e
+	s.count++
+	return e
+}
+
+// Len returns the number of events currently held
+func (s *EventStore) Len() int {
+	return s.count
+}
+
+// At returns the i-th held event, 0 being the oldest
+func (s *EventStore) At(i int) Event {
+	return s.buf[(s.start+i)%len(s.buf)]
+}
+
+// All returns a copy of the held events in arrival order
+func (s *EventStore) All() []Event {
+	events := make([]Event, s.count)
+	for i := range events {
+		events[i] = s.At(i)
+	}
+	return events
+}
+
+// Find returns the held event with the given sequence number. Sequence
+// numbers are consecutive, so its index follows from the newest one.
+func (s *EventStore) Find(seq int) (Event, bool) {
+	i := seq - (s.lastSeq - s.count + 1)
+	if i < 0 || i >= s.count {
+		return Event{}, false
+	}
+	return s.At(i), true
+}
+
+// LastSeq returns the sequence number of the newest event
+func (s *EventStore) LastSeq() int {
+	return s.lastSeq
+}
+
+// Evicted returns how many events have been pushed out of the buffer
+func (s *EventStore) Evicted() int {
+	return s.evicted
+}
+
+// projection is a view onto the event store
+type projection struct {
+	view      *tview.TextView
+	accepts   func(e Event) bool   // which event types the view shows
+	format    func(e Event) string // tagged line for the TextView
+	filtered  bool                 // whether the log filter applies
+	clearedAt int                  // events up to this sequence are hidden
+	firstSeq  int                  // oldest event still in the TextView
+	lines     int                  // lines currently in the TextView
+	dropped   int                  // visible entries trimmed off the top
+	evicted   int                  // entries of this view lost from the store
+}
+
+// eventFromMessage maps an incoming message onto a store event
+func eventFromMessage(msg Message, timestamp string) Event {
+	e := Event{
+		Type:      msg.Type,
+		Level:     msg.Level,
+		Tool:      msg.Tool,
+		Content:   msg.Content,
+		Timestamp: timestamp,
+		Message:   &msg,
+	}
+	switch msg.Type {
+	case "tool", "debug", "raw":
+	case "phase", "file", "project", "lockInfo", "password_request", "approval_request", "state", "summary", "plan":
+		// State changes are stored for exports but not shown in any view
+	default:
+		// Anything else is shown as a log line
+		e.Type = "log"
+		if msg.Type != "log" {
+			e.Level = "info"
+		}
+	}
+	return e
+}
+
+// setupProjections wires each view to the event types it shows
+func (t *TUI) setupProjections() {
+	t.projections = map[string]*projection{
+		"normal": {
+			view: t.mainView,
+			accepts: func(e Event) bool {
+				return e.Type == "log" || e.Type == "tool"
+			},
+			format:   withRegion(t.formatLogEntry),
+			filtered: true,
+		},
+		"debug": {
+			view: t.debugView,
+			accepts: func(e Event) bool {
+				return e.Type == "debug" || e.Type == "tool"
+			},
+			format: withRegion(t.formatDebugEntry),
+		},
+		"raw": {
+			view: t.rawView,
+			accepts: func(e Event) bool {
+				return e.Type == "raw"
+			},
+			format: withRegion(t.formatRawEntry),
+		},
+	}
+}
+
+// eventRegion is the region id of an event's lines in the views
+func eventRegion(seq int) string {
+	return fmt.Sprintf("e%d", seq)
+}
+
+// withRegion makes every entry a region of its view, so it can be clicked,
+// highlighted and jumped to
+func withRegion(format func(e Event) string) func(e Event) string {
+	return func(e Event) string {
+		line := format(e)
+		return fmt.Sprintf(`["%s"]%s[""]`, eventRegion(e.Seq), strings.TrimSuffix(line, "\n")) + "\n"
+	}
+}
+
+// visible reports whether a projection currently shows an event
+func (t *TUI) visible(p *projection, e Event) bool {
+	if e.Seq <= p.clearedAt || !p.accepts(e) {
+		return false
+	}
+	return !p.filtered || t.logFilter.Matches(e)
+}
+
+// record stores an event and appends it to every view that shows it
+func (t *TUI) record(e Event) {
+	e.Time = time.Now()
+	e.Phase = t.phase.Current
+	e.PhaseName = t.phase.Name
+	e = t.events.Append(e)
+	t.noteProblem(e)
+
+	for mode, p := range t.projections {
+		if t.visible(p, e) {
+			line := p.format(e)
+			fmt.Fprint(p.view, line)
+			p.lines += strings.Count(line, "\n")
+			if p.lines > t.config.Scrollback.Lines {
+				t.trimView(mode)
+			}
+		}
+	}
+
+	if err := t.spillErr; err != nil {
+		t.spillErr = nil
+		t.addLog("error", fmt.Sprintf("Failed to open spill file: %v", err), time.Now().Format("15:04:05"))
+	}
+}
+
+// renderView rebuilds a view from the event store
+func (t *TUI) renderView(mode string) {
+	t.layoutView(mode, t.config.Scrollback.Lines)
+	t.updateViewTitle()
+}
+
+// hideView clears a view without touching the stored history
+func (t *TUI) hideView(mode string) {
+	p := t.projections[mode]
+	p.clearedAt = t.events.LastSeq()
+	p.lines, p.dropped, p.evicted = 0, 0, 0
+	p.view.Clear()
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// contents returns the content of the held events, oldest first
+func contents(s *EventStore) []string {
+	var out []string
+	for _, e := range s.All() {
+		out = append(out, e.Content)
+	}
+	return out
+}
+
+func TestEventStoreRing(t *testing.T) {
+	tests := []struct {
+		name     string
+		capacity int
+		appended []string
+		want     []string
+		evicted  []string
+	}{
+		{"empty", 3, nil, nil, nil},
+		{"below capacity", 3, []string{"a", "b"}, []string{"a", "b"}, nil},
+		{"full", 3, []string{"a", "b", "c"}, []string{"a", "b", "c"}, nil},
+		{"one over", 3, []string{"a", "b", "c", "d"}, []string{"b", "c", "d"}, []string{"a"}},
+		{"wrapped twice", 3, []string{"a", "b", "c", "d", "e", "f", "g"}, []string{"e", "f", "g"}, []string{"a", "b", "c", "d"}},
+		{"capacity one", 1, []string{"a", "b"}, []string{"b"}, []string{"a"}},
+		{"capacity below one", 0, []string{"a", "b"}, []string{"b"}, []string{"a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewEventStore(tt.capacity)
+			var evicted []string
+			s.onEvict = func(e Event) { evicted = append(evicted, e.Content) }
+			for i, content := range tt.appended {
+				if e := s.Append(Event{Content: content}); e.Seq != i+1 {
+					t.Fatalf("event %d got sequence number %d", i+1, e.Seq)
+				}
+			}
+			if got := contents(s); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("held %q, want %q", got, tt.want)
+			}
+			if s.Len() != len(tt.want) {
+				t.Errorf("Len = %d, want %d", s.Len(), len(tt.want))
+			}
+			if !reflect.DeepEqual(evicted, tt.evicted) || s.Evicted() != len(tt.evicted) {
+				t.Errorf("evicted %q (%d), want %q", evicted, s.Evicted(), tt.evicted)
+			}
+			if s.LastSeq() != len(tt.appended) {
+				t.Errorf("LastSeq = %d, want %d", s.LastSeq(), len(tt.appended))
+			}
+		})
+	}
+}
+
+func TestEventStoreFind(t *testing.T) {
+	s := NewEventStore(3)
+	for _, content := range []string{"a", "b", "c", "d", "e"} {
+		s.Append(Event{Content: content})
+	}
+	tests := []struct {
+		seq  int
+		want string
+		ok   bool
+	}{
+		{0, "", false},
+		{1, "", false},
+		{2, "", false},
+		{3, "c", true},
+		{4, "d", true},
+		{5, "e", true},
+		{6, "", false},
+		{-1, "", false},
+	}
+	for _, tt := range tests {
+		e, ok := s.Find(tt.seq)
+		if ok != tt.ok || e.Content != tt.want || ok && e.Seq != tt.seq {
+			t.Errorf("Find(%d) = %q (seq %d), %v, want %q, %v", tt.seq, e.Content, e.Seq, ok, tt.want, tt.ok)
+		}
+	}
+}
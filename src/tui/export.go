@@ -0,0 +1,564 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// exportFormat describes one of the formats offered by the export dialog
+type exportFormat struct {
+	id    string
+	label string
+	ext   string
+}
+
+var exportFormats = []exportFormat{
+	{"text", "Plain text", "txt"},
+	{"ansi", "ANSI colored text", "ansi.txt"},
+	{"html", "HTML", "html"},
+	{"markdown", "Markdown", "md"},
+	{"ndjson", "NDJSON (original messages)", "ndjson"},
+}
+
+// exportViews lists the views that can be exported, in dialog order
+var exportViews = []struct {
+	mode  string
+	title string
+}{
+	{"normal", "Logs"},
+	{"debug", "Debug"},
+	{"raw", "Raw API"},
+}
+
+// Range choices in the export dialog
+const (
+	exportRangeAll = iota
+	exportRangeFilter
+	exportRangeTime
+)
+
+// ExportOptions is what the export dialog collects
+type ExportOptions struct {
+	Views  []string
+	Range  int
+	From   time.Time
+	To     time.Time
+	Format string
+	Dir    string
+}
+
+// exportDir is where exports go unless the dialog says otherwise: the
+// configured directory, else the project directory if we know it, else the
+// working directory
+func (t *TUI) exportDir() string {
+	if t.config.ExportDir != "" {
+		return expandHome(t.config.ExportDir)
+	}
+	if info, err := os.Stat(t.projectPath); err == nil && info.IsDir() {
+		return t.projectPath
+	}
+	if wd, err := os.Getwd(); err == nil {
+		return wd
+	}
+	return "."
+}
+
+// exportedEvents returns the events of a view selected by the options
+func (t *TUI) exportedEvents(mode string, opts ExportOptions) []Event {
+	p := t.projections[mode]
+	var events []Event
+	for _, e := range t.events.All() {
+		switch opts.Range {
+		case exportRangeFilter:
+			if !t.visible(p, e) {
+				continue
+			}
+		case exportRangeTime:
+			if !p.accepts(e) || e.Time.Before(opts.From) || e.Time.After(opts.To) {
+				continue
+			}
+		default:
+			if !p.accepts(e) {
+				continue
+			}
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
+// exportedMessages returns the messages of an NDJSON export. All history
+// and time ranges take every stored message, state changes included, not
+// just what the views show; the filter range takes what the selected views
+// show, each message once.
+func (t *TUI) exportedMessages(opts ExportOptions) []Event {
+	var events []Event
+	if opts.Range == exportRangeFilter {
+		seen := map[int]bool{}
+		for _, mode := range opts.Views {
+			for _, e := range t.exportedEvents(mode, opts) {
+				if !seen[e.Seq] {
+					seen[e.Seq] = true
+					events = append(events, e)
+				}
+			}
+		}
+		sort.Slice(events, func(i, j int) bool { return events[i].Seq < events[j].Seq })
+		return events
+	}
+	for _, e := range t.events.All() {
+		if opts.Range == exportRangeTime && (e.Time.Before(opts.From) || e.Time.After(opts.To)) {
+			continue
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
+// writeExport renders the selected events and writes them to a new file
+func (t *TUI) writeExport(opts ExportOptions) (string, int, error) {
+	format := exportFormats[0]
+	for _, f := range exportFormats {
+		if f.id == opts.Format {
+			format = f
+		}
+	}
+
+	var builder strings.Builder
+	count := 0
+	if format.id == "ndjson" {
+		events := t.exportedMessages(opts)
+		for _, e := range events {
+			builder.WriteString(e.originalJSON())
+			builder.WriteString("\n")
+		}
+		count = len(events)
+	} else {
+		if format.id == "html" {
+			builder.WriteString(htmlHeader)
+		}
+		for _, mode := range opts.Views {
+			events := t.exportedEvents(mode, opts)
+			count += len(events)
+			t.writeExportSection(&builder, format.id, mode, events)
+		}
+		if format.id == "html" {
+			builder.WriteString("</body>\n</html>\n")
+		}
+	}
+
+	path, err := writeNewFile(opts.Dir, "documentor_logs", format.ext, []byte(builder.String()))
+	return path, count, err
+}
+
+// writeNewFile writes data to dir/prefix_<timestamp>.ext, numbering the name
+// _2, _3, ... rather than overwriting an export of the same second
+func writeNewFile(dir, prefix, ext string, data []byte) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	stamp := time.Now().Format("20060102_150405")
+	for n := 1; ; n++ {
+		name := fmt.Sprintf("%s_%s.%s", prefix, stamp, ext)
+		if n > 1 {
+			name = fmt.Sprintf("%s_%s_%d.%s", prefix, stamp, n, ext)
+		}
+		path, err := filepath.Abs(filepath.Join(dir, name))
+		if err != nil {
+			return "", err
+		}
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		_, err = f.Write(data)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		return path, err
+	}
+}
+
+// writeExportSection writes the events of one view in a text based format
+func (t *TUI) writeExportSection(b *strings.Builder, format, mode string, events []Event) {
+	p := t.projections[mode]
+	title := mode
+	for _, v := range exportViews {
+		if v.mode == mode {
+			title = v.title
+		}
+	}
+
+	switch format {
+	case "markdown":
+		fmt.Fprintf(b, "## %s\n\n", title)
+		if len(events) == 0 {
+			b.WriteString("_No entries._\n\n")
+			return
+		}
+		b.WriteString("| Time | Level | Phase | Tool | Message |\n")
+		b.WriteString("|------|-------|-------|------|---------|\n")
+		for _, e := range events {
+			level := e.Level
+			if e.Type != "log" {
+				level = e.Type
+			}
+			phase := ""
+			if e.Phase > 0 {
+				phase = fmt.Sprintf("%d %s", e.Phase, e.PhaseName)
+			}
+			fmt.Fprintf(b, "| %s | %s | %s | %s | %s |\n",
+				e.Timestamp, level, markdownCell(phase), markdownCell(e.Tool), markdownCell(e.Content))
+		}
+		b.WriteString("\n")
+	case "html":
+		fmt.Fprintf(b, "<h2>%s</h2>\n<pre>", html.EscapeString(title))
+		for _, e := range events {
+			b.WriteString(tagsToHTML(p.format(e)))
+		}
+		b.WriteString("</pre>\n")
+	case "ansi":
+		fmt.Fprintf(b, "\x1b[1m── %s ──\x1b[0m\n", title)
+		for _, e := range events {
+			b.WriteString(tagsToANSI(p.format(e)))
+		}
+		b.WriteString("\n")
+	default:
+		fmt.Fprintf(b, "── %s ──\n", title)
+		for _, e := range events {
+			b.WriteString(stripTags(p.format(e)))
+		}
+		b.WriteString("\n")
+	}
+}
+
+// originalJSON returns the message as it arrived, or an equivalent message
+// for events the TUI raised itself
+func (e Event) originalJSON() string {
+	if e.Message != nil && e.Message.raw != "" {
+		return e.Message.raw
+	}
+	if e.Message != nil {
+		data, _ := json.Marshal(e.Message)
+		return string(data)
+	}
+	msg := map[string]string{"type": e.Type, "content": e.Content, "timestamp": e.Timestamp}
+	if e.Level != "" {
+		msg["level"] = e.Level
+	}
+	if e.Tool != "" {
+		msg["tool"] = e.Tool
+	}
+	data, _ := json.Marshal(msg)
+	return string(data)
+}
+
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+const htmlHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>docuMentor logs</title>
+<style>
+body { background: #1e1e1e; color: #d4d4d4; font-family: monospace; }
+pre { white-space: pre-wrap; }
+</style>
+</head>
+<body>
+`
+
+// tagSpan is a piece of text with the style given by tview color tags
+type tagSpan struct {
+	text  string
+	fg    string
+	bg    string
+	attrs string
+}
+
+var tagPattern = regexp.MustCompile(`\[([a-zA-Z0-9#]*|-)?(?::([a-zA-Z0-9#]*|-)?)?(?::([a-zA-Z]*|-)?)?\]`)
+var regionPattern = regexp.MustCompile(`^\["[^"]*"\]`)
+var escapedPattern = regexp.MustCompile(`^\[[^\[\]]+\[+\]`)
+
+// parseTags splits tview tagged text into styled spans
+func parseTags(text string) []tagSpan {
+	var spans []tagSpan
+	var current tagSpan
+	var plain strings.Builder
+
+	flush := func() {
+		if plain.Len() > 0 {
+			current.text = plain.String()
+			spans = append(spans, current)
+			plain.Reset()
+		}
+	}
+
+	for len(text) > 0 {
+		if text[0] != '[' {
+			next := strings.IndexByte(text, '[')
+			if next < 0 {
+				next = len(text)
+			}
+			plain.WriteString(text[:next])
+			text = text[next:]
+			continue
+		}
+		if m := escapedPattern.FindString(text); m != "" {
+			// "[red[]" is shown as "[red]"
+			plain.WriteString(m[:len(m)-2] + "]")
+			text = text[len(m):]
+			continue
+		}
+		if m := regionPattern.FindString(text); m != "" {
+			text = text[len(m):]
+			continue
+		}
+		loc := tagPattern.FindStringSubmatchIndex(text)
+		if loc == nil || loc[0] != 0 {
+			plain.WriteByte('[')
+			text = text[1:]
+			continue
+		}
+		part := func(i int) (string, bool) {
+			if loc[2*i] < 0 {
+				return "", false
+			}
+			return text[loc[2*i]:loc[2*i+1]], true
+		}
+		fg, hasFg := part(1)
+		bg, hasBg := part(2)
+		attrs, hasAttrs := part(3)
+
+		flush()
+		if hasFg && fg != "" {
+			current.fg = strings.TrimPrefix(fg, "-")
+		}
+		if hasBg && bg != "" {
+			current.bg = strings.TrimPrefix(bg, "-")
+		}
+		if hasAttrs && attrs != "" {
+			current.attrs = strings.TrimPrefix(attrs, "-")
+		}
+		text = text[loc[1]:]
+	}
+	flush()
+	return spans
+}
+
+// stripTags removes tview tags from text
+func stripTags(text string) string {
+	var b strings.Builder
+	for _, span := range parseTags(text) {
+		b.WriteString(span.text)
+	}
+	return b.String()
+}
+
+// colorRGB resolves a tview color name to its RGB values
+func colorRGB(name string) (int32, int32, int32, bool) {
+	color := tcell.GetColor(name)
+	if color == tcell.ColorDefault {
+		return 0, 0, 0, false
+	}
+	r, g, b := color.RGB()
+	return r, g, b, r >= 0
+}
+
+// tagsToANSI turns tview tags into ANSI escape sequences
+func tagsToANSI(text string) string {
+	attrCodes := map[rune]string{'b': "1", 'd': "2", 'i': "3", 'u': "4", 'l': "5", 'r': "7", 's': "9"}
+
+	var b strings.Builder
+	for _, span := range parseTags(text) {
+		codes := []string{"0"}
+		if r, g, bl, ok := colorRGB(span.fg); ok {
+			codes = append(codes, fmt.Sprintf("38;2;%d;%d;%d", r, g, bl))
+		}
+		if r, g, bl, ok := colorRGB(span.bg); ok {
+			codes = append(codes, fmt.Sprintf("48;2;%d;%d;%d", r, g, bl))
+		}
+		for _, a := range span.attrs {
+			if code, ok := attrCodes[a]; ok {
+				codes = append(codes, code)
+			}
+		}
+		fmt.Fprintf(&b, "\x1b[%sm%s", strings.Join(codes, ";"), span.text)
+	}
+	b.WriteString("\x1b[0m")
+	return b.String()
+}
+
+// tagsToHTML turns tview tags into styled HTML spans
+func tagsToHTML(text string) string {
+	var b strings.Builder
+	for _, span := range parseTags(text) {
+		var styles []string
+		if r, g, bl, ok := colorRGB(span.fg); ok {
+			styles = append(styles, fmt.Sprintf("color:#%02x%02x%02x", r, g, bl))
+		}
+		if r, g, bl, ok := colorRGB(span.bg); ok {
+			styles = append(styles, fmt.Sprintf("background:#%02x%02x%02x", r, g, bl))
+		}
+		if strings.ContainsRune(span.attrs, 'b') {
+			styles = append(styles, "font-weight:bold")
+		}
+		if strings.ContainsRune(span.attrs, 'd') {
+			styles = append(styles, "opacity:0.6")
+		}
+		if strings.ContainsRune(span.attrs, 'i') {
+			styles = append(styles, "font-style:italic")
+		}
+		if strings.ContainsRune(span.attrs, 'u') {
+			styles = append(styles, "text-decoration:underline")
+		}
+		escaped := html.EscapeString(span.text)
+		if len(styles) == 0 {
+			b.WriteString(escaped)
+			continue
+		}
+		fmt.Fprintf(&b, `<span style="%s">%s</span>`, strings.Join(styles, ";"), escaped)
+	}
+	return b.String()
+}
+
+// parseClock reads a YYYY-MM-DD HH:MM[:SS] time, or an HH:MM[:SS] one on
+// the day that puts it within 12 hours of near, so the range of a run past
+// midnight lands on the right days
+func parseClock(text string, near, fallback time.Time) (time.Time, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return fallback, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04"} {
+		if at, err := time.ParseInLocation(layout, text, time.Local); err == nil {
+			return at, nil
+		}
+	}
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if clock, err := time.ParseInLocation(layout, text, time.Local); err == nil {
+			at := time.Date(near.Year(), near.Month(), near.Day(),
+				clock.Hour(), clock.Minute(), clock.Second(), 0, time.Local)
+			switch {
+			case at.Sub(near) > 12*time.Hour:
+				at = at.AddDate(0, 0, -1)
+			case near.Sub(at) > 12*time.Hour:
+				at = at.AddDate(0, 0, 1)
+			}
+			return at, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not HH:MM:SS or YYYY-MM-DD HH:MM:SS", text)
+}
+
+// exportLogs opens the export dialog
+func (t *TUI) exportLogs() {
+	t.modalOpen = true
+
+	form := tview.NewForm()
+	for _, v := range exportViews {
+		form.AddCheckbox(v.title, v.mode == t.viewMode, nil)
+	}
+
+	rangeLabel := "Range"
+	form.AddDropDown(rangeLabel, []string{"All history", "Current filter", "Time range"}, exportRangeAll, nil).
+		AddInputField("From (HH:MM:SS)", "", 20, nil, nil).
+		AddInputField("To (HH:MM:SS)", "", 20, nil, nil)
+
+	var formatLabels []string
+	for _, f := range exportFormats {
+		formatLabels = append(formatLabels, f.label)
+	}
+	form.AddDropDown("Format", formatLabels, 0, nil).
+		AddInputField("Directory", t.exportDir(), 40, nil, nil)
+
+	closeDialog := func() {
+		t.modalOpen = false
+		t.app.SetRoot(t.rootPages, true)
+		t.app.SetFocus(t.getCurrentView())
+	}
+	fail := func(err error) {
+		form.SetTitle(fmt.Sprintf(t.theme.C(" [error]%s[-] "), tview.Escape(err.Error())))
+	}
+
+	form.AddButton("Export", func() {
+		opts := ExportOptions{
+			Dir: strings.TrimSpace(form.GetFormItemByLabel("Directory").(*tview.InputField).GetText()),
+		}
+		for i, v := range exportViews {
+			if form.GetFormItem(i).(*tview.Checkbox).IsChecked() {
+				opts.Views = append(opts.Views, v.mode)
+			}
+		}
+		if len(opts.Views) == 0 {
+			fail(fmt.Errorf("select at least one view"))
+			return
+		}
+		opts.Range, _ = form.GetFormItemByLabel(rangeLabel).(*tview.DropDown).GetCurrentOption()
+		formatIndex, _ := form.GetFormItemByLabel("Format").(*tview.DropDown).GetCurrentOption()
+		opts.Format = exportFormats[formatIndex].id
+		if opts.Dir == "" {
+			opts.Dir = t.exportDir()
+		}
+
+		if opts.Range == exportRangeTime {
+			var err error
+			from := form.GetFormItemByLabel("From (HH:MM:SS)").(*tview.InputField).GetText()
+			if opts.From, err = parseClock(from, t.startTime, t.startTime); err != nil {
+				fail(err)
+				return
+			}
+			// The first such time after From
+			to := form.GetFormItemByLabel("To (HH:MM:SS)").(*tview.InputField).GetText()
+			if opts.To, err = parseClock(to, opts.From.Add(12*time.Hour), time.Now()); err != nil {
+				fail(err)
+				return
+			}
+		}
+
+		path, count, err := t.writeExport(opts)
+		if err != nil {
+			fail(fmt.Errorf("export failed: %v", err))
+			return
+		}
+		closeDialog()
+		t.addLog("success", fmt.Sprintf("Exported %d entries to %s", count, path), time.Now().Format("15:04:05"))
+		t.showToast(fmt.Sprintf("Exported to %s", path))
+	})
+	form.AddButton("Cancel", closeDialog)
+	form.SetCancelFunc(closeDialog)
+
+	form.SetBorder(true).
+		SetTitle(" Export logs ").
+		SetTitleAlign(tview.AlignLeft)
+
+	// Center the form on screen
+	container := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(form, 21, 0, true).
+		AddItem(nil, 0, 1, false)
+	centered := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(container, 70, 0, true).
+		AddItem(nil, 0, 1, false)
+
+	t.app.SetRoot(centered, true)
+	t.app.SetFocus(form)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		name, text string
+		want       []tagSpan
+	}{
+		{"empty", "", nil},
+		{"plain", "hello", []tagSpan{{text: "hello"}}},
+		{"color", "[red]error[white] ok", []tagSpan{{text: "error", fg: "red"}, {text: " ok", fg: "white"}}},
+		{"hex color", "[#ff8000]warm", []tagSpan{{text: "warm", fg: "#ff8000"}}},
+		{"background and attributes", "[yellow:blue:bu]x", []tagSpan{{text: "x", fg: "yellow", bg: "blue", attrs: "bu"}}},
+		{"attributes keep the color", "[red]a[::b]b", []tagSpan{{text: "a", fg: "red"}, {text: "b", fg: "red", attrs: "b"}}},
+		{"reset", "[red::b]a[-:-:-]b", []tagSpan{{text: "a", fg: "red", attrs: "b"}, {text: "b"}}},
+		{"reset attributes", "[green::u]a[white::-]b", []tagSpan{{text: "a", fg: "green", attrs: "u"}, {text: "b", fg: "white"}}},
+		{"region", `["e12"]line[""]`, []tagSpan{{text: "line"}}},
+		{"escaped tag", "[red[]", []tagSpan{{text: "[red]"}}},
+		{"escaped in text", "see [docs[] here", []tagSpan{{text: "see [docs] here"}}},
+		{"not a tag", "a [b c] d", []tagSpan{{text: "a [b c] d"}}},
+		{"unclosed", "x[red", []tagSpan{{text: "x[red"}}},
+		{"empty tag", "a[]b", []tagSpan{{text: "a"}, {text: "b"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseTags(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTags(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTagsToANSI(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"", "\x1b[0m"},
+		{"plain", "\x1b[0mplain\x1b[0m"},
+		{"[red]error", "\x1b[0;38;2;255;0;0merror\x1b[0m"},
+		{"[#102030:#405060]x", "\x1b[0;38;2;16;32;48;48;2;64;80;96mx\x1b[0m"},
+		{"[::bu]x", "\x1b[0;1;4mx\x1b[0m"},
+		{"[::bz]x", "\x1b[0;1mx\x1b[0m"},
+		{"[red]a[-]b", "\x1b[0;38;2;255;0;0ma\x1b[0mb\x1b[0m"},
+		{"[nosuchcolor]x", "\x1b[0mx\x1b[0m"},
+		{"[red[]", "\x1b[0m[red]\x1b[0m"},
+	}
+	for _, tt := range tests {
+		if got := tagsToANSI(tt.text); got != tt.want {
+			t.Errorf("tagsToANSI(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2024, 3, day, hour, min, 0, 0, time.Local)
+	}
+	fallback := at(1, 0, 0)
+	tests := []struct {
+		name string
+		text string
+		near time.Time
+		want time.Time
+	}{
+		{"empty", "", at(10, 12, 0), fallback},
+		{"same day", "14:30", at(10, 12, 0), at(10, 14, 30)},
+		{"seconds", "14:30:00", at(10, 12, 0), at(10, 14, 30)},
+		{"after midnight", "00:30", at(10, 23, 0), at(11, 0, 30)},
+		{"before midnight", "23:30", at(11, 1, 0), at(10, 23, 30)},
+		{"full date", "2024-03-05 08:15", at(10, 12, 0), at(5, 8, 15)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseClock(tt.text, tt.near, fallback)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseClock(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+	if _, err := parseClock("noon", at(10, 12, 0), fallback); err == nil {
+		t.Error("parseClock accepted a malformed time")
+	}
+}
+
+func TestWriteNewFile(t *testing.T) {
+	dir := t.TempDir()
+	first, err := writeNewFile(dir, "export", "txt", []byte("one"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := writeNewFile(dir, "export", "txt", []byte("two"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Fatalf("both exports written to %s", first)
+	}
+	if data, _ := os.ReadFile(first); string(data) != "one" {
+		t.Errorf("first export overwritten: %q", data)
+	}
+	if filepath.Dir(second) != dir {
+		t.Errorf("second export written to %s", second)
+	}
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// Documentation status of a file in the file tree
+const (
+	filePending    = "pending"
+	fileInProgress = "in_progress"
+	fileDone       = "done"
+	fileFailed     = "failed"
+	fileSkipped    = "skipped"
+)
+
+// fileStatusStyle is the glyph and theme role of a file status
+type fileStatusStyle struct {
+	status string
+	icon   string
+	role   string
+}
+
+// fileStatusStyles lists the statuses in the order their counts are shown
+var fileStatusStyles = []fileStatusStyle{
+	{fileDone, "\uf00c", "success"},
+	{fileInProgress, "\uf110", "info"},
+	{filePending, "\uf10c", "muted"},
+	{fileFailed, "\uf00d", "error"},
+	{fileSkipped, "\uf05e", "muted"},
+}
+
+// styleOf returns the style of a status, pending for unknown ones
+func styleOf(status string) fileStatusStyle {
+	for _, style := range fileStatusStyles {
+		if style.status == status {
+			return style
+		}
+	}
+	return fileStatusStyles[2]
+}
+
+// fileTools matches the tools that work on a single file, writeTools the
+// ones that change it
+var (
+	fileTools  = regexp.MustCompile(`(?i)read|writ|edit|audit`)
+	writeTools = regexp.MustCompile(`(?i)writ|edit`)
+)
+
+// fileSet is the status of every file the agent reported or planned
+type fileSet struct {
+	status      map[string]string // project relative path -> status
+	current     string            // file in progress
+	lastWritten string            // last file a Write or Edit tool call reported
+	dirty       bool              // the tree is out of date
+}
+
+func newFileSet() *fileSet {
+	return &fileSet{status: map[string]string{}, dirty: true}
+}
+
+// setupFiles creates the file tree panel left of the logs view
+func (t *TUI) setupFiles() {
+	t.filesTree = tview.NewTreeView().
+		SetRoot(tview.NewTreeNode(".")).
+		SetTopLevel(1)
+	t.filesTree.SetBorder(true).
+		SetTitleAlign(tview.AlignLeft)
+	t.updateFiles()
+}
+
+// noteFiles updates file statuses from a message: plan messages list the
+// files to document, file and tool messages start one and errors fail it
+func (t *TUI) noteFiles(msg Message) {
+	set := t.fileSet
+	switch msg.Type {
+	case "plan":
+		for _, path := range msg.Plan {
+			if path = t.projectFile(path); path != "" && set.status[path] == "" {
+				set.status[path] = filePending
+				set.dirty = true
+			}
+		}
+		if len(msg.Plan) > 0 && !t.filesShown {
+			// A plan is worth looking at, show the tree
+			t.filesShown = true
+			t.fitPanels()
+		}
+	case "file":
+		status := msg.Files.Status
+		if status == "" && !looksLikePath(msg.Files.Current) {
+			// Searches and commands come through as the current "file" too
+			return
+		}
+		t.setFileStatus(msg.Files.Current, status)
+	case "tool":
+		if fileTools.MatchString(msg.Tool) && looksLikePath(msg.Content) {
+			t.setFileStatus(msg.Content, "")
+			if writeTools.MatchString(msg.Tool) {
+				set.lastWritten = msg.Content
+			}
+		}
+	case "log":
+		if msg.Level == "error" && set.current != "" && set.status[set.current] == fileInProgress {
+			set.status[set.current] = fileFailed
+			set.dirty = true
+		}
+	}
+	t.updateFiles()
+}
+
+// setFileStatus sets the status of a file; no status means it is being
+// worked on, which finishes the file worked on before
+func (t *TUI) setFileStatus(path, status string) {
+	set := t.fileSet
+	path = t.projectFile(path)
+	if path == "" {
+		return
+	}
+	if status == "" {
+		status = fileInProgress
+	}
+	if status == fileInProgress {
+		if set.current != "" && set.current != path && set.status[set.current] == fileInProgress {
+			set.status[set.current] = fileDone
+		}
+		set.current = path
+	}
+	if set.status[path] != status {
+		set.status[path] = status
+		set.dirty = true
+	}
+}
+
+// finishFiles settles the files still in progress when the run ends
+func (t *TUI) finishFiles(completed bool) {
+	status := fileFailed
+	if completed {
+		status = fileDone
+	}
+	for path, s := range t.fileSet.status {
+		if s == fileInProgress {
+			t.fileSet.status[path] = status
+			t.fileSet.dirty = true
+		}
+	}
+	t.updateFiles()
+}
+
+// projectFile turns a reported path into a slash separated path relative to
+// the project, or "" if it doesn't name a file
+func (t *TUI) projectFile(path string) string {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return ""
+	}
+	if filepath.IsAbs(path) && filepath.IsAbs(t.projectPath) {
+		if rel, err := filepath.Rel(t.projectPath, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+	}
+	path = filepath.ToSlash(filepath.Clean(path))
+	if path == "." || strings.HasSuffix(path, "/") {
+		return ""
+	}
+	return path
+}
+
+// looksLikePath tells file names from search patterns and commands, which
+// the agent reports the same way
+func looksLikePath(text string) bool {
+	if text == "" || strings.ContainsAny(text, " \t\n*?{}|<>") {
+		return false
+	}
+	return strings.Contains(text, "/") || filepath.Ext(text) != ""
+}
+
+// fileDir is a directory of the file tree while it is built
+type fileDir struct {
+	dirs   map[string]*fileDir
+	files  []string // full paths
+	counts map[string]int
+	total  int
+}
+
+func newFileDir() *fileDir {
+	return &fileDir{dirs: map[string]*fileDir{}, counts: map[string]int{}}
+}
+
+// updateFiles rebuilds the tree if it changed and can be seen, keeping the
+// selection and collapsed directories
+func (t *TUI) updateFiles() {
+	set := t.fileSet
+	t.updateFilesTitle()
+	if !set.dirty || !t.filesVisible() {
+		// A hidden tree is rebuilt when it is shown again
+		return
+	}
+	set.dirty = false
+
+	var selected interface{}
+	if node := t.filesTree.GetCurrentNode(); node != nil {
+		selected = node.GetReference()
+	}
+	collapsed := map[string]bool{}
+	t.filesTree.GetRoot().Walk(func(node, parent *tview.TreeNode) bool {
+		if key, ok := node.GetReference().(string); ok && strings.HasSuffix(key, "/") && !node.IsExpanded() {
+			collapsed[key] = true
+		}
+		return true
+	})
+
+	// Directories with their counts, then the tree nodes
+	top := newFileDir()
+	for path, status := range set.status {
+		parts := strings.Split(path, "/")
+		dir := top
+		dir.counts[status]++
+		dir.total++
+		for _, name := range parts[:len(parts)-1] {
+			if dir.dirs[name] == nil {
+				dir.dirs[name] = newFileDir()
+			}
+			dir = dir.dirs[name]
+			dir.counts[status]++
+			dir.total++
+		}
+		dir.files = append(dir.files, path)
+	}
+
+	th := t.theme
+	var current *tview.TreeNode
+	var add func(parent *tview.TreeNode, dir *fileDir, prefix string)
+	add = func(parent *tview.TreeNode, dir *fileDir, prefix string) {
+		names := make([]string, 0, len(dir.dirs))
+		for name := range dir.dirs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			sub := dir.dirs[name]
+			key := prefix + name + "/"
+			node := tview.NewTreeNode(tview.Escape(name+"/") + " " + t.fileCounts(sub.counts, sub.total)).
+				SetReference(key).
+				SetColor(th.Color("label")).
+				SetExpanded(!collapsed[key])
+			node.SetSelectedFunc(func() { node.SetExpanded(!node.IsExpanded()) })
+			parent.AddChild(node)
+			if key == selected {
+				current = node
+			}
+			add(node, sub, key)
+		}
+		sort.Strings(dir.files)
+		for _, path := range dir.files {
+			style := styleOf(set.status[path])
+			node := tview.NewTreeNode(style.icon + " " + tview.Escape(filepath.Base(path))).
+				SetReference(path).
+				SetColor(th.Color(style.role))
+			file := path
+			node.SetSelectedFunc(func() { t.showPreview(file) })
+			parent.AddChild(node)
+			if path == selected || (selected == nil && path == set.current) {
+				current = node
+			}
+		}
+	}
+	root := tview.NewTreeNode(".")
+	add(root, top, "")
+	if len(set.status) == 0 {
+		root.AddChild(tview.NewTreeNode("No files reported yet").
+			SetColor(th.Color("muted")).
+			SetSelectable(false))
+	}
+
+	t.filesTree.SetRoot(root)
+	if current == nil && len(root.GetChildren()) > 0 {
+		current = root.GetChildren()[0]
+	}
+	if current != nil {
+		t.filesTree.SetCurrentNode(current)
+	}
+}
+
+// fileCounts renders the done/total count of a directory, plus the failed
+// files if there are any
+func (t *TUI) fileCounts(counts map[string]int, total int) string {
+	th := t.theme
+	text := fmt.Sprintf("%s%d/%d%s", th.Tag("muted"), counts[fileDone]+counts[fileSkipped], total, th.Tag("label"))
+	if counts[fileFailed] > 0 {
+		text += fmt.Sprintf(" %s\uf00d %d%s", th.Tag("error"), counts[fileFailed], th.Tag("label"))
+	}
+	return text
+}
+
+// updateFilesTitle shows the totals per status in the panel border
+func (t *TUI) updateFilesTitle() {
+	th := t.theme
+	title := " files "
+	for _, style := range fileStatusStyles {
+		count := 0
+		for _, status := range t.fileSet.status {
+			if status == style.status {
+				count++
+			}
+		}
+		if count > 0 {
+			title += fmt.Sprintf("%s%s %d%s ", th.Tag(style.role), style.icon, count, th.Tag("text"))
+		}
+	}
+	t.filesTree.SetTitle(title)
+}
+
+// toggleFiles shows or hides the file tree
+func (t *TUI) toggleFiles() {
+	t.filesShown = !t.filesShown
+	if t.viewMode != "normal" {
+		t.switchView("normal")
+	}
+	t.fitPanels()
+	if !t.filesShown && t.focusedWidget == "files" {
+		t.focusedWidget = "main"
+		t.app.SetFocus(t.getCurrentView())
+		t.updateShortcuts()
+	}
+	t.updateFiles()
+}
+
+// filesVisible reports whether the file tree takes up space
+func (t *TUI) filesVisible() bool {
+	return t.filesShown && t.layout != "minimal"
+}
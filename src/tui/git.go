@@ -0,0 +1,435 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// maxCommitFiles is how many files the generated commit message names
+const maxCommitFiles = 20
+
+// docExtensions are the documentation file types; changed ones the run
+// didn't write are only committed on request
+var docExtensions = map[string]bool{".md": true, ".mdx": true, ".markdown": true, ".rst": true, ".adoc": true}
+
+// gitChange is a line of git status
+type gitChange struct {
+	status         string // the two letter porcelain status, "??" untracked
+	path           string // relative to the repository
+	added, removed int    // lines changed against HEAD, -1 if unknown
+	written        bool   // written by the run, committed by the commit action
+	doc            bool   // a documentation file type, committed with otherDocs
+	rejected       bool   // rejected on the review page, never committed
+}
+
+// gitPanel is the open git page
+type gitPanel struct {
+	dirs      []string // directories to look at: the project and the output directory
+	dir       int
+	root      string // repository of the current directory
+	changes   []gitChange
+	message   string // commit message, generated until edited
+	edited    bool
+	otherDocs bool // commit the changed docs the run didn't write too
+	confirm   bool // C was pressed once, the next C commits
+	view      *tview.TextView
+	footer    *tview.TextView
+	page      *tview.Flex
+}
+
+// git runs git in a directory and returns its output
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s", msg)
+		}
+		return "", err
+	}
+	return string(out), nil
+}
+
+// gitDirs returns the directories the panel can show: the project, and the
+// output directory if the summary names another one
+func (t *TUI) gitDirs() []string {
+	var dirs []string
+	if filepath.IsAbs(t.projectPath) || t.projectPath == "." {
+		dirs = append(dirs, t.projectPath)
+	}
+	if t.summary != nil && t.summary.OutputDir != "" {
+		out := t.resolvePath(t.summary.OutputDir)
+		if !containsString(dirs, out) {
+			dirs = append(dirs, out)
+		}
+	}
+	return dirs
+}
+
+// showGit opens the git page for the project
+func (t *TUI) showGit() {
+	dirs := t.gitDirs()
+	if len(dirs) == 0 {
+		t.showToast("No project path reported yet")
+		return
+	}
+	g := &gitPanel{
+		dirs: dirs,
+		view: tview.NewTextView().
+			SetDynamicColors(true).
+			SetScrollable(true).
+			SetWrap(false),
+		footer: tview.NewTextView().SetDynamicColors(true),
+	}
+	g.view.SetBorder(true).
+		SetBorderPadding(0, 0, 1, 1).
+		SetTitleAlign(tview.AlignLeft)
+	g.footer.SetBorder(true).
+		SetBorderPadding(0, 0, 1, 1)
+	g.page = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(g.view, 0, 1, true).
+		AddItem(g.footer, 3, 0, false)
+
+	g.page.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		confirm := g.confirm
+		g.confirm = false
+		switch {
+		case event.Key() == tcell.KeyEsc:
+			t.modalOpen = false
+			t.app.SetRoot(t.rootPages, true)
+			t.app.SetFocus(t.getCurrentView())
+			return nil
+		case event.Rune() == 'q' || event.Rune() == 'Q':
+			t.app.Stop()
+			return nil
+		case event.Rune() == 'r' || event.Rune() == 'R':
+			t.loadGit(g)
+		case event.Rune() == 'o' || event.Rune() == 'O':
+			if len(g.dirs) > 1 {
+				g.dir = (g.dir + 1) % len(g.dirs)
+				t.loadGit(g)
+			}
+		case event.Rune() == 'm' || event.Rune() == 'M':
+			if message, err := t.editText(g.message, "documentor-commit-*.txt"); err != nil {
+				t.showToast(err.Error())
+			} else if strings.TrimSpace(message) != "" {
+				g.message, g.edited = message, true
+			}
+			t.renderGit(g)
+		case event.Rune() == 'a' || event.Rune() == 'A':
+			if g.hasOtherDocs() {
+				g.otherDocs = !g.otherDocs
+				if !g.edited {
+					g.message = t.commitMessage(g.docs())
+				}
+				t.renderGit(g)
+			}
+		case event.Rune() == 'c' || event.Rune() == 'C':
+			docs := g.docs()
+			if len(docs) == 0 {
+				t.showToast("No documentation changes to commit")
+				break
+			}
+			if !confirm {
+				g.confirm = true
+				g.footer.SetText(fmt.Sprintf(t.theme.C("[warning]Stage and commit %d doc file(s) in %s? Press C again to confirm, any other key cancels[text]"),
+					len(docs), tview.Escape(g.root)))
+				return nil
+			}
+			t.commitDocs(g)
+		default:
+			return event
+		}
+		t.updateGitFooter(g)
+		return nil
+	})
+
+	t.loadGit(g)
+	t.updateGitFooter(g)
+	t.modalOpen = true
+	t.app.SetRoot(g.page, true)
+	t.app.SetFocus(g.view)
+}
+
+// loadGit reads the status of the current directory's repository
+func (t *TUI) loadGit(g *gitPanel) {
+	g.root, g.changes = "", nil
+	dir := g.dirs[g.dir]
+	root, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		g.view.SetTitle(" \ue702 git ")
+		g.view.SetText(fmt.Sprintf(t.theme.C("[error]%s is not in a git repository[text]\n\n[muted]%s[text]"),
+			tview.Escape(dir), tview.Escape(err.Error())))
+		return
+	}
+	g.root = strings.TrimSpace(root)
+
+	// Only the directory's own changes, which is all of them for the project
+	out, err := git(g.root, "status", "--porcelain", "-z", "--untracked-files=all", "--", dir)
+	if err != nil {
+		g.view.SetText(fmt.Sprintf(t.theme.C("[error]git status: %s[text]"), tview.Escape(err.Error())))
+		return
+	}
+	stats := map[string][2]int{}
+	if numstat, err := git(g.root, "diff", "--numstat", "-z", "HEAD", "--", dir); err == nil {
+		// A repository without commits has no HEAD to diff against
+		stats = parseNumstat(numstat)
+	}
+	written := map[string]bool{}
+	rejected := map[string]bool{}
+	for _, path := range t.reviewFiles() {
+		if rel, err := filepath.Rel(g.root, t.resolvePath(path)); err == nil {
+			written[filepath.ToSlash(rel)] = true
+			rejected[filepath.ToSlash(rel)] = t.reviewMark(path) == reviewRejected
+		}
+	}
+
+	entries := strings.Split(strings.TrimSuffix(out, "\x00"), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		c := gitChange{status: entry[:2], path: entry[3:], added: -1, removed: -1}
+		if c.status[0] == 'R' || c.status[0] == 'C' {
+			// The original path follows
+			i++
+		}
+		if s, ok := stats[c.path]; ok {
+			c.added, c.removed = s[0], s[1]
+		}
+		c.written = written[c.path]
+		c.doc = docExtensions[strings.ToLower(filepath.Ext(c.path))]
+		c.rejected = rejected[c.path]
+		g.changes = append(g.changes, c)
+	}
+	if !g.edited {
+		g.message = t.commitMessage(g.docs())
+	}
+	t.renderGit(g)
+}
+
+// parseNumstat reads the added and removed lines per file of git diff
+// --numstat -z; binary files count as unknown
+func parseNumstat(out string) map[string][2]int {
+	stats := map[string][2]int{}
+	fields := strings.Split(out, "\x00")
+	for i := 0; i < len(fields); i++ {
+		parts := strings.SplitN(fields[i], "\t", 3)
+		if len(parts) < 3 {
+			continue
+		}
+		path := parts[2]
+		if path == "" && i+2 < len(fields) {
+			// A rename: the old and the new path follow
+			path = fields[i+2]
+			i += 2
+		}
+		added, err1 := strconv.Atoi(parts[0])
+		removed, err2 := strconv.Atoi(parts[1])
+		if err1 != nil || err2 != nil {
+			added, removed = -1, -1
+		}
+		stats[path] = [2]int{added, removed}
+	}
+	return stats
+}
+
+// docs returns the documentation changes the commit action takes: what the
+// run wrote, and the other changed docs if asked for
+func (g *gitPanel) docs() []gitChange {
+	var docs []gitChange
+	for _, c := range g.changes {
+		if !c.rejected && (c.written || g.otherDocs && c.doc) {
+			docs = append(docs, c)
+		}
+	}
+	return docs
+}
+
+// hasOtherDocs reports whether docs the run didn't write changed
+func (g *gitPanel) hasOtherDocs() bool {
+	for _, c := range g.changes {
+		if c.doc && !c.written {
+			return true
+		}
+	}
+	return false
+}
+
+// renderGit shows the changes grouped into the run's documentation, the
+// other docs and the rest, then the commit message
+func (t *TUI) renderGit(g *gitPanel) {
+	if g.root == "" {
+		return
+	}
+	th := t.theme
+	g.view.SetTitle(fmt.Sprintf(" \ue702 %s %s%s%s ", tview.Escape(g.root), th.Tag("muted"), gitBranch(g.root), th.Tag("text")))
+
+	var docs, rejected, otherDocs, other []gitChange
+	for _, c := range g.changes {
+		switch {
+		case c.written && c.rejected:
+			rejected = append(rejected, c)
+		case c.written:
+			docs = append(docs, c)
+		case c.doc:
+			otherDocs = append(otherDocs, c)
+		default:
+			other = append(other, c)
+		}
+	}
+
+	var out strings.Builder
+	if len(g.changes) == 0 {
+		out.WriteString(th.C("[success]Nothing to commit, the working tree is clean.[text]\n"))
+	}
+	section := func(title, role string, changes []gitChange) {
+		if len(changes) == 0 {
+			return
+		}
+		added, removed := 0, 0
+		for _, c := range changes {
+			added += max(c.added, 0)
+			removed += max(c.removed, 0)
+		}
+		fmt.Fprintf(&out, "%s[::b]%s (%d)[::-]%s  %s+%d%s %s-%d%s\n", th.Tag(role), title, len(changes), th.Tag("text"),
+			th.Tag("success"), added, th.Tag("text"), th.Tag("error"), removed, th.Tag("text"))
+		for _, c := range changes {
+			t.writeGitChange(&out, c)
+		}
+		out.WriteString("\n")
+	}
+	section("Documentation", "accent", docs)
+	section("Rejected on the review page, not committed", "error", rejected)
+	if g.otherDocs {
+		section("Other docs, not written by the run, committed too", "warning", otherDocs)
+	} else {
+		section("Other docs, not written by the run, not committed", "muted", otherDocs)
+	}
+	section("Other changes, not committed", "muted", other)
+
+	if len(g.docs()) > 0 {
+		title := "Commit message"
+		if g.edited {
+			title += " (edited)"
+		}
+		fmt.Fprintf(&out, "%s[::b]%s[::-]%s\n", th.Tag("label"), title, th.Tag("text"))
+		for _, line := range strings.Split(strings.TrimRight(g.message, "\n"), "\n") {
+			out.WriteString("  " + tview.Escape(line) + "\n")
+		}
+	}
+	g.view.SetText(out.String())
+}
+
+// writeGitChange writes one status line
+func (t *TUI) writeGitChange(out *strings.Builder, c gitChange) {
+	th := t.theme
+	role, label := "warning", strings.TrimSpace(c.status)
+	switch {
+	case c.status == "??":
+		role, label = "success", "new"
+	case strings.Contains(c.status, "D"):
+		role, label = "error", "deleted"
+	case strings.Contains(c.status, "A"):
+		role, label = "success", "added"
+	case strings.Contains(c.status, "R"):
+		label = "renamed"
+	case strings.Contains(c.status, "M"):
+		label = "modified"
+	}
+	stat := ""
+	if c.added >= 0 {
+		stat = fmt.Sprintf("  %s+%d%s %s-%d%s", th.Tag("success"), c.added, th.Tag("text"), th.Tag("error"), c.removed, th.Tag("text"))
+	}
+	fmt.Fprintf(out, "  %s%s%s %s%s\n", th.Tag(role), padRight(label, 9), th.Tag("text"), tview.Escape(c.path), stat)
+}
+
+// gitBranch names the checked out branch, or "" on a detached HEAD
+func gitBranch(root string) string {
+	branch, err := git(root, "branch", "--show-current")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(branch)
+}
+
+// commitMessage summarizes the run's phases and files
+func (t *TUI) commitMessage(docs []gitChange) string {
+	s := t.runSummary()
+	project := filepath.Base(t.projectPath)
+	var b strings.Builder
+	fmt.Fprintf(&b, "docs: update generated documentation for %s\n\n", project)
+	fmt.Fprintf(&b, "Generated by documentor in %s: %d file(s) changed.\n", formatElapsed(time.Duration(s.ElapsedSeconds*float64(time.Second))), len(docs))
+	if len(s.Phases) > 0 {
+		b.WriteString("\nPhases:\n")
+		for _, p := range s.Phases {
+			fmt.Fprintf(&b, "- %s (%s)\n", p.Name, formatElapsed(time.Duration(p.Seconds*float64(time.Second))))
+		}
+	}
+	b.WriteString("\nFiles:\n")
+	for i, c := range docs {
+		if i == maxCommitFiles {
+			fmt.Fprintf(&b, "- and %d more\n", len(docs)-maxCommitFiles)
+			break
+		}
+		fmt.Fprintf(&b, "- %s\n", c.path)
+	}
+	return b.String()
+}
+
+// commitDocs stages the documentation changes and commits only those,
+// leaving whatever else is staged alone. The paths are literal pathspecs,
+// so a doc named with *, ? or [ does not pull in other files.
+func (t *TUI) commitDocs(g *gitPanel) {
+	var paths []string
+	for _, c := range g.docs() {
+		paths = append(paths, c.path)
+	}
+	timestamp := time.Now().Format("15:04:05")
+	if _, err := git(g.root, append([]string{"--literal-pathspecs", "add", "-A", "--"}, paths...)...); err != nil {
+		t.addLog("error", fmt.Sprintf("git add: %v", err), timestamp)
+		t.showToast("Staging failed, see the logs")
+		return
+	}
+	cmd := exec.Command("git", append([]string{"-C", g.root, "--literal-pathspecs", "commit", "--file=-", "--only", "--"}, paths...)...)
+	cmd.Stdin = strings.NewReader(g.message)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.addLog("error", fmt.Sprintf("git commit: %s", strings.TrimSpace(string(out))), timestamp)
+		t.showToast("Commit failed, see the logs")
+		return
+	}
+	hash, _ := git(g.root, "rev-parse", "--short", "HEAD")
+	t.addLog("success", fmt.Sprintf("Committed %d doc file(s) as %s in %s", len(paths), strings.TrimSpace(hash), g.root), timestamp)
+	t.showToast("Committed " + strings.TrimSpace(hash))
+	g.edited = false
+	t.loadGit(g)
+}
+
+// updateGitFooter shows the keys of the git page
+func (t *TUI) updateGitFooter(g *gitPanel) {
+	keys := "[label]C[text] commit the docs   [label]M[text] edit the message   [label]R[text] refresh   "
+	if g.hasOtherDocs() {
+		if g.otherDocs {
+			keys += "[label]A[text] leave out the other docs   "
+		} else {
+			keys += "[label]A[text] add the other docs   "
+		}
+	}
+	if len(g.dirs) > 1 {
+		other := g.dirs[(g.dir+1)%len(g.dirs)]
+		keys += "[label]O[text] show " + tview.Escape(filepath.Base(other)) + "   "
+	}
+	g.footer.SetText(t.theme.C(keys + "[label]Esc[text] back"))
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rivo/tview"
+)
+
+func TestParseNumstat(t *testing.T) {
+	tests := []struct {
+		name, out string
+		want      map[string][2]int
+	}{
+		{"empty", "", map[string][2]int{}},
+		{"one file", "3\t1\tdocs/api.md\x00", map[string][2]int{"docs/api.md": {3, 1}}},
+		{
+			"several",
+			"3\t1\tdocs/api.md\x000\t12\tREADME.md\x00",
+			map[string][2]int{"docs/api.md": {3, 1}, "README.md": {0, 12}},
+		},
+		{"binary", "-\t-\tlogo.png\x00", map[string][2]int{"logo.png": {-1, -1}}},
+		{"spaces and tabs kept", "1\t0\tmy docs/a\tb.md\x00", map[string][2]int{"my docs/a\tb.md": {1, 0}}},
+		{
+			"rename, counted under the new path",
+			"1\t0\t\x00docs/old.md\x00docs/new.md\x002\t2\tkeep.txt\x00",
+			map[string][2]int{"docs/new.md": {1, 0}, "keep.txt": {2, 2}},
+		},
+		{"no trailing NUL", "4\t0\tguide.md", map[string][2]int{"guide.md": {4, 0}}},
+		{"garbage", "not numstat\x00", map[string][2]int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseNumstat(tt.out); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseNumstat(%q) = %v, want %v", tt.out, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitPanelDocs(t *testing.T) {
+	changes := []gitChange{
+		{path: "docs/api.md", written: true, doc: true},
+		{path: "docs/rejected.md", written: true, doc: true, rejected: true},
+		{path: "docs/notes.txt", written: true},
+		{path: "CHANGELOG.md", doc: true},
+		{path: "main.go"},
+	}
+	tests := []struct {
+		name      string
+		otherDocs bool
+		want      []string
+	}{
+		{"written by the run", false, []string{"docs/api.md", "docs/notes.txt"}},
+		{"with the other docs", true, []string{"docs/api.md", "docs/notes.txt", "CHANGELOG.md"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &gitPanel{changes: changes, otherDocs: tt.otherDocs}
+			var got []string
+			for _, c := range g.docs() {
+				got = append(got, c.path)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("docs = %q, want %q", got, tt.want)
+			}
+			if !g.hasOtherDocs() {
+				t.Error("hasOtherDocs = false, want true")
+			}
+		})
+	}
+}
+
+func TestCommitDocsLiteralPaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.name", "test"},
+		{"config", "user.email", "test@example.com"},
+	} {
+		if _, err := git(dir, args...); err != nil {
+			t.Skipf("git unavailable: %v", err)
+		}
+	}
+	// As a glob, a[1].md would match a1.md too
+	for _, name := range []string{"a[1].md", "a1.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("# doc\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tui := NewTUI(DefaultConfig())
+	g := &gitPanel{
+		dirs:    []string{dir},
+		root:    dir,
+		changes: []gitChange{{status: "??", path: "a[1].md", written: true}},
+		message: "docs",
+		view:    tview.NewTextView(),
+		footer:  tview.NewTextView(),
+	}
+	tui.commitDocs(g)
+
+	out, err := git(dir, "ls-files")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Fields(out); !reflect.DeepEqual(got, []string{"a[1].md"}) {
+		t.Errorf("committed %q, want only a[1].md", got)
+	}
+}
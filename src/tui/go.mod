@@ -4,13 +4,13 @@ go 1.25.0
 
 require (
 	github.com/gdamore/tcell/v2 v2.9.0
+	github.com/mattn/go-runewidth v0.0.16
 	github.com/rivo/tview v0.42.0
 )
 
 require (
 	github.com/gdamore/encoding v1.0.1 // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
-	github.com/mattn/go-runewidth v0.0.16 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
 	golang.org/x/sys v0.35.0 // indirect
 	golang.org/x/term v0.34.0 // indirect
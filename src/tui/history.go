@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// sparkBlocks are the bar heights of a sparkline, lowest first
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// series is a fixed-size ring of samples, one per second
+type series struct {
+	buf   []float64
+	start int
+	count int
+}
+
+func newSeries(capacity int) *series {
+	return &series{buf: make([]float64, capacity)}
+}
+
+// push adds a sample, dropping the oldest when full
+func (s *series) push(v float64) {
+	if s.count < len(s.buf) {
+		s.buf[(s.start+s.count)%len(s.buf)] = v
+		s.count++
+		return
+	}
+	s.buf[s.start] = v
+	s.start = (s.start + 1) % len(s.buf)
+}
+
+// values returns the samples oldest first
+func (s *series) values() []float64 {
+	values := make([]float64, s.count)
+	for i := range values {
+		values[i] = s.buf[(s.start+i)%len(s.buf)]
+	}
+	return values
+}
+
+// resourceHistory keeps the last few minutes of agent resource use and
+// message activity for the trends panel
+type resourceHistory struct {
+	memory   *series // agent memory, MB
+	cpu      *series // agent CPU, percent of one core
+	messages *series // messages received per second
+	files    *series // files finished per second
+
+	messageCount  int // messages since the last sample
+	lastProcessed int // files.Processed at the last sample
+}
+
+func newResourceHistory(seconds int) *resourceHistory {
+	return &resourceHistory{
+		memory:   newSeries(seconds),
+		cpu:      newSeries(seconds),
+		messages: newSeries(seconds),
+		files:    newSeries(seconds),
+	}
+}
+
+// sampleHistory records one second of history; called with each process sample
+func (t *TUI) sampleHistory() {
+	h := t.history
+	memory := float64(t.agentStats.RSS) / (1 << 20)
+	if !t.agentStats.Alive && t.agentStats.ReportedMB > 0 {
+		memory = float64(t.agentStats.ReportedMB)
+	}
+	h.memory.push(memory)
+	h.cpu.push(t.agentStats.CPUPercent)
+	h.messages.push(float64(h.messageCount))
+	h.messageCount = 0
+
+	done := t.files.Processed - h.lastProcessed
+	if done < 0 {
+		// A new run started counting from zero
+		done = 0
+	}
+	h.files.push(float64(done))
+	h.lastProcessed = t.files.Processed
+}
+
+// sparkline draws values as width bars, averaging neighbouring samples when
+// there are more samples than bars. It also returns the newest bar's value
+// and the highest one.
+func sparkline(values []float64, width int) (line string, last, peak float64) {
+	if width < 1 || len(values) == 0 {
+		return "", 0, 0
+	}
+	buckets := values
+	if len(values) > width {
+		buckets = make([]float64, width)
+		for i := range buckets {
+			from, to := i*len(values)/width, (i+1)*len(values)/width
+			sum := 0.0
+			for _, v := range values[from:to] {
+				sum += v
+			}
+			buckets[i] = sum / float64(to-from)
+		}
+	}
+	for _, v := range buckets {
+		if v > peak {
+			peak = v
+		}
+	}
+
+	var builder strings.Builder
+	for _, v := range buckets {
+		level := 0
+		if peak > 0 {
+			level = int(v / peak * float64(len(sparkBlocks)-1))
+		}
+		builder.WriteRune(sparkBlocks[level])
+	}
+	return builder.String(), buckets[len(buckets)-1], peak
+}
+
+// setupTrends creates the trends panel of the debug view
+func (t *TUI) setupTrends() {
+	t.trendsView = tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(false)
+	t.trendsView.SetBorder(true).
+		SetTitle(fmt.Sprintf(" trends, last %dm ", t.config.Monitor.HistoryMinutes)).
+		SetTitleAlign(tview.AlignLeft)
+}
+
+// updateTrends redraws the sparklines to fit the panel
+func (t *TUI) updateTrends() {
+	_, _, width, _ := t.trendsView.GetInnerRect()
+	// Room for the label and the value columns
+	width -= 28
+	if width < 10 {
+		width = 10
+	}
+
+	h := t.history
+	rows := []struct {
+		label  string
+		role   string
+		values []float64
+		format func(last, peak float64) string
+	}{
+		{"Memory", "info", h.memory.values(), func(last, peak float64) string {
+			values := h.memory.values()
+			growth := values[len(values)-1] - values[0]
+			return fmt.Sprintf("%.0fMB %+.0fMB", last, growth)
+		}},
+		{"CPU", "success", h.cpu.values(), func(last, peak float64) string {
+			return fmt.Sprintf("%.0f%% max %.0f%%", last, peak)
+		}},
+		{"Msgs/s", "tool", h.messages.values(), func(last, peak float64) string {
+			return fmt.Sprintf("%.1f max %.0f", last, peak)
+		}},
+		{"Files/min", "label3", h.files.values(), func(last, peak float64) string {
+			return fmt.Sprintf("%.0f max %.0f", last*60, peak*60)
+		}},
+	}
+
+	var builder strings.Builder
+	for i, row := range rows {
+		if i > 0 {
+			builder.WriteString("\n")
+		}
+		if len(row.values) == 0 {
+			builder.WriteString(fmt.Sprintf(t.theme.C("[label2]%-9s[text] [muted]no data yet[text]"), row.label))
+			continue
+		}
+		line, last, peak := sparkline(row.values, width)
+		builder.WriteString(fmt.Sprintf(t.theme.C("[label2]%-9s[text] %s%s[text] %s"),
+			row.label, t.theme.Tag(row.role), line, row.format(last, peak)))
+	}
+	t.trendsView.SetText(builder.String())
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Breakpoints of the responsive layout. The full layout also needs room for
+// the configured panel heights plus minLogLines of logs.
+const (
+	fullWidth     = 100
+	compactWidth  = 60
+	compactHeight = 14
+	minWidth      = 40
+	minHeight     = 8
+	minLogLines   = 10
+
+	// Width of the file tree
+	filesWidth        = 40
+	filesWidthCompact = 28
+)
+
+// layoutFor picks the layout that fits a terminal of the given size: full,
+// compact (one-line info, icon buttons), minimal (logs and a status line)
+// or small when not even that fits
+func (t *TUI) layoutFor(width, height int) string {
+	l := t.config.Layout
+	switch {
+	case width >= fullWidth && height >= 1+l.InfoHeight+l.ButtonsHeight+l.FooterHeight+minLogLines:
+		return "full"
+	case width >= compactWidth && height >= compactHeight:
+		return "compact"
+	case width >= minWidth && height >= minHeight:
+		return "minimal"
+	}
+	return "small"
+}
+
+// setupLayout creates the panels of the smaller layouts and switches layouts
+// whenever the terminal size calls for another one
+func (t *TUI) setupLayout() {
+	t.statusLine = tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(false)
+	t.statusLine.SetBorderPadding(0, 0, 1, 1)
+
+	t.tooSmall = tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter)
+
+	t.applyLayout("full")
+	t.app.SetBeforeDrawFunc(func(screen tcell.Screen) bool {
+		// Runs inside the draw with the application locked, so nothing
+		// here may call back into t.app
+		width, height := screen.Size()
+		layout := t.layoutFor(width, height)
+		if layout == "small" {
+			t.tooSmall.SetText(fmt.Sprintf(t.theme.C(
+				"\n[warning]Terminal too small[text]\n\n%dx%d, needs at least %dx%d\n\n[muted]q quits[text]"),
+				width, height, minWidth, minHeight))
+		}
+		t.applyLayout(layout)
+		return false
+	})
+}
+
+// applyLayout rearranges the main layout, if the layout changed
+func (t *TUI) applyLayout(layout string) {
+	if layout == t.layout {
+		return
+	}
+	t.layout = layout
+
+	// Switching pages would move the focus, which needs the application
+	// lock, so the notice takes the place of the layout instead
+	l := t.config.Layout
+	t.mainLayout.Clear()
+	switch layout {
+	case "small":
+		t.mainLayout.AddItem(t.tooSmall, 0, 1, false)
+	case "full":
+		t.footerBox.SetBorder(true)
+		t.mainLayout.
+			AddItem(t.headerBar, 1, 0, false).
+			AddItem(t.headerFlex, l.InfoHeight, 0, false).
+			AddItem(t.shortcutsBox, l.ButtonsHeight, 0, false).
+			AddItem(t.pages, 0, 1, true).
+			AddItem(t.footerBox, l.FooterHeight, 0, false)
+	case "compact":
+		t.footerBox.SetBorder(false)
+		t.mainLayout.
+			AddItem(t.statusLine, 1, 0, false).
+			AddItem(t.shortcutsBox, 1, 0, false).
+			AddItem(t.pages, 0, 1, true).
+			AddItem(t.footerBox, 1, 0, false)
+	case "minimal":
+		t.mainLayout.
+			AddItem(t.pages, 0, 1, true).
+			AddItem(t.statusLine, 1, 0, false)
+	}
+	t.fitPanels()
+	t.updateShortcuts()
+	t.updateStatusLine()
+}
+
+// fitPanels sizes the side panels for the layout. The minimal layout has
+// room for the logs only.
+func (t *TUI) fitPanels() {
+	problems, debug, files := 0, 2, 0
+	if t.problemsVisible() {
+		problems = 1
+	}
+	if t.filesVisible() {
+		files = filesWidth
+		if t.layout == "compact" {
+			files = filesWidthCompact
+		}
+	}
+	if t.layout == "minimal" {
+		debug = 0
+	}
+	t.normalPage.ResizeItem(t.problemsTree, 0, problems)
+	t.debugPage.ResizeItem(t.debugPanels, 0, debug)
+	t.normalSplit.ResizeItem(t.filesTree, files, 0)
+	if debug == 0 && (t.focusedWidget == "problems" || t.focusedWidget == "procs" || t.focusedWidget == "files") {
+		t.focusedWidget = "main"
+		// The application is locked while the layout changes
+		go t.app.QueueUpdate(func() { t.app.SetFocus(t.getCurrentView()) })
+	}
+}
+
+// infoPanels returns the panels of the current layout that only show information
+func (t *TUI) infoPanels() []*tview.TextView {
+	switch t.layout {
+	case "full":
+		return []*tview.TextView{t.headerBar, t.infoBox, t.statsBox, t.footerBox}
+	case "compact":
+		return []*tview.TextView{t.statusLine, t.footerBox}
+	case "minimal":
+		return []*tview.TextView{t.statusLine}
+	}
+	return nil
+}
+
+// updateStatusLine fills the one-line status of the compact and minimal
+// layouts. In the minimal layout it stands in for the footer too.
+func (t *TUI) updateStatusLine() {
+	if t.statusLine == nil || (t.layout != "compact" && t.layout != "minimal") {
+		return
+	}
+	if t.layout == "minimal" {
+		if notice := t.footerNotice(); notice != "" {
+			t.statusLine.SetText(notice)
+			return
+		}
+	}
+
+	th := t.theme
+	elapsed := time.Since(t.startTime)
+	if !t.endTime.IsZero() {
+		elapsed = t.endTime.Sub(t.startTime)
+	}
+	icon, label := t.stateDisplay()
+	line := fmt.Sprintf("%s %s %s", icon, label, formatElapsed(elapsed))
+
+	if t.projectPath != "" && t.projectPath != "." && t.projectPath != "No project loaded" {
+		line += th.C("  [muted]│[text] ") + tview.Escape(truncateEnd(filepath.Base(t.projectPath), 24))
+	}
+	if t.phase.Total > 0 || t.phase.Name != "" {
+		line += fmt.Sprintf(th.C("  [muted]│[label2] phase[text] %d/%d %s"),
+			t.phase.Current, t.phase.Total, tview.Escape(truncateEnd(t.phase.Name, 24)))
+	}
+	line += fmt.Sprintf(th.C("  [muted]│[label] files[text] %d/%d"), t.files.Processed, t.files.Total)
+	if t.problems.warnings > 0 {
+		line += fmt.Sprintf("  %s\uf071 %d%s", th.Tag("warning"), t.problems.warnings, th.Tag("text"))
+	}
+	if t.problems.errors > 0 {
+		line += fmt.Sprintf("  %s\uf00d %d%s", th.Tag("error"), t.problems.errors, th.Tag("text"))
+	}
+	t.statusLine.SetText(line)
+}
@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+func TestLayoutFor(t *testing.T) {
+	// The default panels need 1+6+3+3 lines plus minLogLines for the full layout
+	tui := &TUI{config: DefaultConfig()}
+	tests := []struct {
+		width, height int
+		want          string
+	}{
+		{200, 60, "full"},
+		{100, 23, "full"},
+		{99, 23, "compact"},
+		{100, 22, "compact"},
+		{60, 14, "compact"},
+		{59, 14, "minimal"},
+		{60, 13, "minimal"},
+		{40, 8, "minimal"},
+		{39, 8, "small"},
+		{40, 7, "small"},
+		{0, 0, "small"},
+	}
+	for _, tt := range tests {
+		if got := tui.layoutFor(tt.width, tt.height); got != tt.want {
+			t.Errorf("layoutFor(%d, %d) = %s, want %s", tt.width, tt.height, got, tt.want)
+		}
+	}
+
+	// Taller panels need a taller terminal
+	tui.config.Layout.InfoHeight = 10
+	if got := tui.layoutFor(100, 23); got != "compact" {
+		t.Errorf("layoutFor(100, 23) with an info height of 10 = %s, want compact", got)
+	}
+}
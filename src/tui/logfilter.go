@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// logLevels lists the level toggles in the order they appear in the dialog
+var logLevels = []string{"info", "success", "warning", "error", "tool"}
+
+// LogFilter decides which events are visible in the logs view
+type LogFilter struct {
+	Hidden  map[string]bool // levels switched off
+	Tool    string          // only tool calls whose name contains this
+	Phase   int             // only lines from this phase, 0 = any
+	Include *regexp.Regexp
+	Exclude *regexp.Regexp
+}
+
+// Active reports whether the filter hides anything
+func (f LogFilter) Active() bool {
+	for _, hidden := range f.Hidden {
+		if hidden {
+			return true
+		}
+	}
+	return f.Tool != "" || f.Phase > 0 || f.Include != nil || f.Exclude != nil
+}
+
+// Matches reports whether an event passes the filter
+func (f LogFilter) Matches(e Event) bool {
+	if f.Hidden[e.levelKey()] {
+		return false
+	}
+	if f.Tool != "" && !strings.Contains(strings.ToLower(e.Tool), strings.ToLower(f.Tool)) {
+		return false
+	}
+	if f.Phase > 0 && e.Phase != f.Phase {
+		return false
+	}
+	if f.Include != nil && !f.Include.MatchString(e.Content) {
+		return false
+	}
+	if f.Exclude != nil && f.Exclude.MatchString(e.Content) {
+		return false
+	}
+	return true
+}
+
+// Badge returns a short description of the active filter for the view title
+func (f LogFilter) Badge(th *Theme) string {
+	if !f.Active() {
+		return ""
+	}
+
+	var parts []string
+	var shown []string
+	for _, level := range logLevels {
+		if !f.Hidden[level] {
+			shown = append(shown, level)
+		}
+	}
+	if len(shown) < len(logLevels) {
+		parts = append(parts, strings.Join(shown, "+"))
+	}
+	if f.Tool != "" {
+		parts = append(parts, "tool "+f.Tool)
+	}
+	if f.Phase > 0 {
+		parts = append(parts, fmt.Sprintf("phase %d", f.Phase))
+	}
+	if f.Include != nil {
+		parts = append(parts, "/"+f.Include.String()+"/")
+	}
+	if f.Exclude != nil {
+		parts = append(parts, "!/"+f.Exclude.String()+"/")
+	}
+	return th.C("[badge] filter: ") + tview.Escape(strings.Join(parts, ", ")) + " [-:-:-]"
+}
+
+// showFilterDialog opens the log filter form
+func (t *TUI) showFilterDialog() {
+	t.modalOpen = true
+
+	f := t.logFilter
+	form := tview.NewForm()
+	for _, level := range logLevels {
+		form.AddCheckbox(strings.ToUpper(level[:1])+level[1:], !f.Hidden[level], nil)
+	}
+
+	phaseText := ""
+	if f.Phase > 0 {
+		phaseText = strconv.Itoa(f.Phase)
+	}
+	includeText, excludeText := "", ""
+	if f.Include != nil {
+		includeText = f.Include.String()
+	}
+	if f.Exclude != nil {
+		excludeText = f.Exclude.String()
+	}
+
+	form.AddInputField("Tool", f.Tool, 30, nil, nil).
+		AddInputField("Phase", phaseText, 5, tview.InputFieldInteger, nil).
+		AddInputField("Include regex", includeText, 30, nil, nil).
+		AddInputField("Exclude regex", excludeText, 30, nil, nil)
+
+	closeDialog := func() {
+		t.modalOpen = false
+		t.app.SetRoot(t.rootPages, true)
+		t.app.SetFocus(t.getCurrentView())
+	}
+
+	form.AddButton("Apply", func() {
+		filter := LogFilter{Hidden: map[string]bool{}}
+		for i, level := range logLevels {
+			if !form.GetFormItem(i).(*tview.Checkbox).IsChecked() {
+				filter.Hidden[level] = true
+			}
+		}
+		filter.Tool = strings.TrimSpace(form.GetFormItemByLabel("Tool").(*tview.InputField).GetText())
+		filter.Phase, _ = strconv.Atoi(form.GetFormItemByLabel("Phase").(*tview.InputField).GetText())
+
+		var err error
+		if text := form.GetFormItemByLabel("Include regex").(*tview.InputField).GetText(); text != "" {
+			if filter.Include, err = regexp.Compile(text); err != nil {
+				form.SetTitle(fmt.Sprintf(t.theme.C(" [error]Invalid include regex: %v[-] "), err))
+				return
+			}
+		}
+		if text := form.GetFormItemByLabel("Exclude regex").(*tview.InputField).GetText(); text != "" {
+			if filter.Exclude, err = regexp.Compile(text); err != nil {
+				form.SetTitle(fmt.Sprintf(t.theme.C(" [error]Invalid exclude regex: %v[-] "), err))
+				return
+			}
+		}
+
+		t.logFilter = filter
+		closeDialog()
+		t.switchView("normal")
+		t.renderView("normal")
+	})
+	form.AddButton("Reset", func() {
+		t.logFilter = LogFilter{Hidden: map[string]bool{}}
+		closeDialog()
+		t.renderView("normal")
+	})
+	form.AddButton("Cancel", closeDialog)
+	form.SetCancelFunc(closeDialog)
+
+	form.SetBorder(true).
+		SetTitle(" Filter logs ").
+		SetTitleAlign(tview.AlignLeft)
+
+	// Center the form on screen
+	container := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(form, 22, 0, true).
+		AddItem(nil, 0, 1, false)
+	centered := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(container, 60, 0, true).
+		AddItem(nil, 0, 1, false)
+
+	t.app.SetRoot(centered, true)
+	t.app.SetFocus(form)
+}
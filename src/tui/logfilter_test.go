@@ -0,0 +1,41 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestLogFilterMatches(t *testing.T) {
+	entry := Event{Type: "log", Level: "info", Phase: 2, Content: "Wrote docs/api.md"}
+	tool := Event{Type: "tool", Tool: "Read", Phase: 1, Content: "src/main.go"}
+	tests := []struct {
+		name   string
+		filter LogFilter
+		e      Event
+		want   bool
+	}{
+		{"no filter", LogFilter{}, entry, true},
+		{"hidden level", LogFilter{Hidden: map[string]bool{"info": true}}, entry, false},
+		{"other level hidden", LogFilter{Hidden: map[string]bool{"error": true}}, entry, true},
+		{"tools hidden", LogFilter{Hidden: map[string]bool{"tool": true}}, tool, false},
+		{"tool name", LogFilter{Tool: "read"}, tool, true},
+		{"other tool", LogFilter{Tool: "write"}, tool, false},
+		{"phase", LogFilter{Phase: 2}, entry, true},
+		{"other phase", LogFilter{Phase: 1}, entry, false},
+		{"include", LogFilter{Include: regexp.MustCompile(`api\.md$`)}, entry, true},
+		{"include misses", LogFilter{Include: regexp.MustCompile(`guide`)}, entry, false},
+		{"exclude", LogFilter{Exclude: regexp.MustCompile(`^Wrote`)}, entry, false},
+		{"exclude misses", LogFilter{Exclude: regexp.MustCompile(`guide`)}, entry, true},
+		{"include and exclude", LogFilter{Include: regexp.MustCompile(`docs/`), Exclude: regexp.MustCompile(`api`)}, entry, false},
+		{"include without the exclude", LogFilter{Include: regexp.MustCompile(`docs/`), Exclude: regexp.MustCompile(`guide`)}, entry, true},
+		{"case sensitive", LogFilter{Include: regexp.MustCompile(`wrote`)}, entry, false},
+		{"case insensitive", LogFilter{Include: regexp.MustCompile(`(?i)wrote`)}, entry, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(tt.e); got != tt.want {
+				t.Errorf("Matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
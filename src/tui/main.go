@@ -3,14 +3,17 @@ package main
 import (
 	"bufio"
 	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
-	"runtime"
-	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
 	"github.com/rivo/tview"
 )
 
@@ -28,6 +31,12 @@ type Message struct {
 	Data        interface{} `json:"data,omitempty"`
 	ProjectPath string      `json:"projectPath,omitempty"`
 	LockInfo    LockInfo    `json:"lockInfo,omitempty"`
+	State       string      `json:"state,omitempty"` // for "state" messages
+	Summary     *RunSummary `json:"summary,omitempty"` // for "summary" messages
+	Plan        []string    `json:"plan,omitempty"`    // for "plan" messages: the files to document
+	Change      *ToolChange `json:"change,omitempty"`  // for Write and Edit "tool" messages: what they replaced
+	
+	raw string // the line as received, kept for NDJSON exports
 }
 
 type PhaseInfo struct {
@@ -41,6 +50,7 @@ type FileInfo struct {
 	Processed int    `json:"processed"`
 	Total     int    `json:"total"`
 	Current   string `json:"current"`
+	Status    string `json:"status,omitempty"` // of Current: pending, in_progress, done, failed or skipped
 }
 
 type LockInfo struct {
@@ -72,6 +82,10 @@ type TUI struct {
 	pages         *tview.Pages
 	rootPages     *tview.Pages  // Root pages for modal overlay
 	mainLayout    *tview.Flex   // Main layout flex
+	headerFlex    *tview.Flex     // Info and stats panels of the full layout
+	statusLine    *tview.TextView // One-line status of the compact and minimal layouts
+	tooSmall      *tview.TextView // Shown instead of the layout when nothing fits
+	layout        string          // full, compact, minimal or small, see layoutFor
 	
 	// State
 	startTime     time.Time
@@ -85,24 +99,90 @@ type TUI struct {
 	processStats  ProcessStats
 	spinnerIndex  int
 	spinnerChars  []string
-	focusedWidget string // "main", "shortcuts"
+	focusedWidget string // "main", "procs", "problems", "files", "shortcuts"
 	selectedBtn   int
 	modalOpen     bool   // Track if modal is open
+	toast         string    // Transient footer notice
+	toastUntil    time.Time
+	events        *EventStore // Recent events, views are projections of it
+	config        *Config
+	bindings      map[string]string   // Key sequence -> action id
+	actionKeys    map[string][]string // Action id -> key sequences
+	pendingKeys   string              // Started chord, e.g. "g"
+	pendingAt     time.Time
+	buttonState   string              // Enabled buttons when the row was last drawn
+	spillFile     *os.File   // Where evicted events go, if configured
+	spillErr      error      // Spill file failure, logged once the evicting event is stored
+	projections   map[string]*projection
+	logFilter     LogFilter  // Which log entries are visible
+	agentStats    AgentStats   // Sampled from /proc
+	agentPID      atomic.Int64 // Process sampled for agentStats
+	agentCmd      *exec.Cmd    // The agent, if the TUI launched it
+	agentStatus   atomic.Int64 // Exit status of the launched agent, -1 while it runs
+	input         io.Reader    // Message stream: stdin or the agent's output
+	fixedPID      bool         // agentPID came from --agent-pid
+	procTable     *tview.Table // Process tree panel of the debug view
+	procRows      []procRow    // Last process tree sample
+	history       *resourceHistory
+	trendsView    *tview.TextView // Sparklines of the debug view
+	state         RunState
+	stateSince    time.Time
+	endTime       time.Time // When the run ended, stops the elapsed clock
+	theme         *Theme
+	runTally      runTally    // Warnings, errors and phase timings counted here
+	summary       *RunSummary // Sent by the agent at the end of the run
+	summaryShown  bool        // The results page has been offered for this run
+	summaryView   *tview.TextView // The results page while it is open
+	screen        *focusScreen    // The terminal, once Run has set it up
+	problems      *problemSet     // Warnings and errors, de-duplicated
+	problemsTree  *tview.TreeView // Problems panel below the logs view
+	problemsHidden bool           // The user closed the problems panel
+	normalPage    *tview.Flex     // Logs view and problems panel
+	debugPage     *tview.Flex     // Debug view and debugPanels
+	normalSplit   *tview.Flex     // File tree and normalPage
+	fileSet       *fileSet        // Status of every reported file
+	filesTree     *tview.TreeView // File tree panel left of the logs view
+	filesShown    bool            // The user or a plan opened the file tree
+	changes       *changeSet      // Files as first seen and every Write and Edit call
+	replies       chan []byte     // Answers to the agent, nil without a return channel
+	approvals     approvalGate    // Write approvals waiting for an answer
+	approvalPage  *approvals      // The approval page while it is open
+	reviewMarks   map[string]string // Review mark of every written file reviewed so far
+	debugPanels   *tview.Flex     // Process tree and trends
+	scrollBarOffset int           // Where the scroll indicator starts in the view title, -1 if not shown
+	draggingScroll  bool          // The scroll indicator is being dragged
+	selecting     bool            // Line selection is on in the current view
+	selCursor     int             // Event under the selection cursor
+	selAnchor     int             // Other end of a selected range, 0 for none
 }
 
-func NewTUI() *TUI {
+func NewTUI(cfg *Config) *TUI {
 	tui := &TUI{
 		app:           tview.NewApplication(),
 		startTime:     time.Now(),
 		lastUpdate:    time.Now(),
 		viewMode:      "normal",
 		pid:           os.Getpid(),
-		spinnerChars:  []string{"◐", "◓", "◑", "◒"},
+		spinnerChars:  cfg.Spinner,
 		spinnerIndex:  0,
 		focusedWidget: "main",
 		selectedBtn:   0,
 		projectPath:   "No project loaded",
+		config:        cfg,
+		events:        NewEventStore(cfg.Scrollback.History),
+		theme:         cfg.ResolveTheme(),
+		history:       newResourceHistory(cfg.Monitor.HistoryMinutes * 60),
+		state:         StateWaiting,
+		scrollBarOffset: -1,
+		problems:      newProblemSet(),
+		fileSet:       newFileSet(),
+		changes:       newChangeSet(),
+		reviewMarks:   map[string]string{},
+		stateSince:    time.Now(),
 	}
+	tui.theme.Apply()
+	tui.bindings, tui.actionKeys, _ = cfg.keymap()
+	tui.events.onEvict = tui.evict
 	
 	// Create header bar - CENTERED
 	tui.headerBar = tview.NewTextView().
@@ -138,6 +218,7 @@ func NewTUI() *TUI {
 		SetScrollable(true).
 		SetWrap(true).          // Enable text wrapping
 		SetWordWrap(true).      // Wrap at word boundaries
+		SetRegions(true).       // Every entry is a region, see withRegion
 		SetChangedFunc(func() {
 			tui.app.Draw()
 		})
@@ -146,6 +227,7 @@ func NewTUI() *TUI {
 	
 	tui.debugView = tview.NewTextView().
 		SetDynamicColors(true).
+		SetRegions(true).
 		SetScrollable(true).
 		SetWrap(true).          // Enable text wrapping
 		SetWordWrap(true)       // Wrap at word boundaries
@@ -154,12 +236,23 @@ func NewTUI() *TUI {
 	
 	tui.rawView = tview.NewTextView().
 		SetDynamicColors(true).
+		SetRegions(true).
 		SetScrollable(true).
 		SetWrap(true).          // Enable text wrapping
 		SetWordWrap(true)       // Wrap at word boundaries
 	tui.rawView.SetBorder(true).
 		SetTitleAlign(tview.AlignLeft)
 	
+	tui.setupProjections()
+	tui.setupProcTable()
+	tui.setupTrends()
+	tui.setupProblems()
+	tui.setupFiles()
+	for _, p := range tui.projections {
+		// Views follow new lines until the user scrolls up
+		p.view.ScrollToEnd()
+	}
+	
 	// Create footer status bar - NO TITLE
 	tui.footerBox = tview.NewTextView().
 		SetDynamicColors(true).
@@ -167,106 +260,85 @@ func NewTUI() *TUI {
 	tui.footerBox.SetBorder(true).
 		SetBorderPadding(0, 0, 1, 1)
 	
+	// The problems panel stays out of the way until there is a problem
+	tui.normalPage = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tui.mainView, 0, 3, true).
+		AddItem(tui.problemsTree, 0, 0, false)
+	
+	// The file tree stays hidden until it is opened or a plan arrives
+	tui.normalSplit = tview.NewFlex().
+		AddItem(tui.filesTree, 0, 0, false).
+		AddItem(tui.normalPage, 0, 1, true)
+	
+	// Process tree and trends below the debug view
+	tui.debugPanels = tview.NewFlex().
+		AddItem(tui.procTable, 0, 3, false).
+		AddItem(tui.trendsView, 0, 2, false)
+	tui.debugPage = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tui.debugView, 0, 3, true).
+		AddItem(tui.debugPanels, 0, 2, false)
+	
 	// Create pages for different views
 	tui.pages = tview.NewPages().
-		AddPage("normal", tui.mainView, true, true).
-		AddPage("debug", tui.debugView, true, false).
+		AddPage("normal", tui.normalSplit, true, true).
+		AddPage("debug", tui.debugPage, true, false).
 		AddPage("raw", tui.rawView, true, false)
 	
 	// Create header flex (horizontal) - equal heights for info and stats
-	headerFlex := tview.NewFlex().SetDirection(tview.FlexColumn).
-		AddItem(tui.infoBox, 0, 3, true).     // 75% width
-		AddItem(tui.statsBox, 0, 1, false)    // 25% width
-	
-	// Create main layout (vertical) with title
-	tui.mainLayout = tview.NewFlex().SetDirection(tview.FlexRow).
-		AddItem(tui.headerBar, 1, 0, false).     // 1. docuMentor title at top
-		AddItem(headerFlex, 6, 0, false).        // 2. Info + Stats panels
-		AddItem(tui.shortcutsBox, 3, 0, false).  // 3. Button row (styled TextViews with borders)
-		AddItem(tui.pages, 0, 1, true).          // 4. Main logs area
-		AddItem(tui.footerBox, 3, 0, false)      // 5. Footer status bar
+	tui.headerFlex = tview.NewFlex().SetDirection(tview.FlexColumn).
+		AddItem(tui.infoBox, 0, cfg.Layout.InfoWidth, true).     // 75% width by default
+		AddItem(tui.statsBox, 0, cfg.Layout.StatsWidth, false)   // 25% width by default
+	
+	// Create main layout (vertical), filled in by applyLayout
+	tui.mainLayout = tview.NewFlex().SetDirection(tview.FlexRow)
 	
 	// Create root pages for modal overlay support
 	tui.rootPages = tview.NewPages().
 		AddPage("main", tui.mainLayout, true, true)
+	tui.setupLayout()
 	
 	// Set up key handlers
 	tui.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		switch event.Key() {
-		case tcell.KeyTab:
-			tui.switchFocus()
-			return nil
-		case tcell.KeyLeft:
-			if tui.focusedWidget == "shortcuts" && tui.selectedBtn > 0 {
-				tui.selectedBtn--
-				tui.updateShortcuts()
-			}
-			return nil
-		case tcell.KeyRight:
-			if tui.focusedWidget == "shortcuts" && tui.selectedBtn < 5 {
-				tui.selectedBtn++
-				tui.updateShortcuts()
-			}
-			return nil
-		case tcell.KeyEnter:
-			// If modal is open, let the modal handle it
-			if tui.modalOpen {
-				return event
-			}
-			if tui.focusedWidget == "shortcuts" {
-				tui.executeShortcut(tui.selectedBtn)
-			}
-			return nil
-		case tcell.KeyEsc:
-			// If modal is open, let the modal handle it
-			if tui.modalOpen {
-				return event
-			}
-			tui.app.Stop()
-			return nil
-		case tcell.KeyCtrlC:
-			tui.app.Stop()
-			return nil
-		case tcell.KeyRune:
-			// Block all shortcuts when modal is open
-			if tui.modalOpen {
-				return event
-			}
-			
-			switch event.Rune() {
-			case 'q', 'Q':
-				tui.app.Stop()
-				return nil
-			case 'd', 'D':
-				tui.switchView("debug")
-				return nil
-			case 'r', 'R':
-				tui.switchView("raw")
-				return nil
-			case 'n', 'N':
-				tui.switchView("normal")
-				return nil
-			case 'c', 'C':
-				tui.clearCurrentView()
+		// Let an open modal handle everything except force quit
+		if tui.modalOpen && event.Key() != tcell.KeyCtrlC {
+			return event
+		}
+		
+		// Arrow keys and Enter drive the button row while it has focus
+		if tui.focusedWidget == "shortcuts" {
+			switch event.Key() {
+			case tcell.KeyLeft:
+				if tui.selectedBtn > 0 {
+					tui.selectedBtn--
+					tui.updateShortcuts()
+				}
 				return nil
-			case 'e', 'E':
-				tui.exportLogs()
+			case tcell.KeyRight:
+				if tui.selectedBtn < len(tui.buttonActions())-1 {
+					tui.selectedBtn++
+					tui.updateShortcuts()
+				}
 				return nil
-			case 'p', 'P':
-				// Test password modal
-				tui.testSimplePasswordModal()
+			case tcell.KeyEnter:
+				tui.executeShortcut(tui.selectedBtn)
 				return nil
 			}
-		case tcell.KeyPgUp:
-			tui.scrollCurrentView(-10)
+		}
+		
+		if event.Key() == tcell.KeyCtrlC {
+			tui.app.Stop()
+			return nil
+		}
+		if tui.selecting && tui.handleSelectionKey(event) {
 			return nil
-		case tcell.KeyPgDn:
-			tui.scrollCurrentView(10)
+		}
+		if tui.handleKey(event) {
 			return nil
 		}
 		return event
 	})
 	
+	tui.setupMouse()
 	tui.app.SetRoot(tui.rootPages, true)
 	tui.updateHeader()
 	tui.updateInfoBox()
@@ -282,38 +354,56 @@ func NewTUI() *TUI {
 
 func (t *TUI) updateHeader() {
 	header := fmt.Sprintf(
-		"[white::b]                            docuMentor v%s                            [::-]",
+		t.theme.C("[header]                            docuMentor v%s                            [::-]"),
 		VERSION,
 	)
 	t.headerBar.SetText(header)
 }
 
 func (t *TUI) switchFocus() {
-	if t.focusedWidget == "main" {
+	switch {
+	case t.layout == "minimal":
+		// Only the view is left to focus
+		t.focusedWidget = "main"
+	case t.focusedWidget == "main" && t.viewMode == "debug":
+		// The process tree takes focus so its rows can be selected
+		t.focusedWidget = "procs"
+	case t.focusedWidget == "main" && t.viewMode == "normal" && t.problemsVisible():
+		t.focusedWidget = "problems"
+	case (t.focusedWidget == "main" || t.focusedWidget == "problems") && t.viewMode == "normal" && t.filesVisible():
+		t.focusedWidget = "files"
+	case t.focusedWidget == "main" || t.focusedWidget == "procs" || t.focusedWidget == "problems" || t.focusedWidget == "files":
 		t.focusedWidget = "shortcuts"
-	} else {
+	default:
 		t.focusedWidget = "main"
 	}
+	t.app.SetFocus(t.getCurrentView())
 	t.updateShortcuts()
 }
 
 func (t *TUI) updateShortcuts() {
 	t.shortcutsBox.Clear()
 	
-	buttons := []struct {
-		key   string
-		label string
-		mode  string
-	}{
-		{"N", "Normal", "normal"},
-		{"D", "Debug", "debug"},
-		{"R", "Raw", "raw"},
-		{"C", "Clear", ""},
-		{"E", "Export", ""},
-		{"Q", "Quit", ""},
-	}
+	buttons := t.buttonActions()
+	th := t.theme
+	state := make([]bool, 0, len(buttons))
 	
 	for i, btn := range buttons {
+		key := ""
+		if keys := t.actionKeys[btn.ID]; len(keys) > 0 {
+			key = tview.Escape("["+displayKey(keys[0])+"]") + " "
+		}
+		label := th.Tag("buttonText")
+		if !btn.enabled(t) {
+			label = th.Tag("muted")
+		}
+		state = append(state, btn.enabled(t))
+		text := key + btn.Button
+		if t.layout == "compact" {
+			// No room for labels, the help overlay has the keys
+			text = btn.Icon
+		}
+		
 		// Create a TextView that looks like a button
 		btnView := tview.NewTextView().
 			SetDynamicColors(true).
@@ -324,65 +414,50 @@ func (t *TUI) updateShortcuts() {
 		var btnText string
 		if t.focusedWidget == "shortcuts" && i == t.selectedBtn {
 			// Selected/focused button
-			btnView.SetBackgroundColor(tcell.ColorLightBlue)
-			btnText = fmt.Sprintf("[black::b] [[%s]] %s [-:-:-]", btn.key, btn.label)
-		} else if btn.mode != "" && btn.mode == t.viewMode {
+			btnView.SetBackgroundColor(th.Color("buttonSelected"))
+			btnText = fmt.Sprintf("%s %s [-:-:-]", th.Tag("buttonSelectedText"), text)
+		} else if btn.Mode != "" && btn.Mode == t.viewMode {
 			// Active mode button
-			btnView.SetBackgroundColor(tcell.ColorDarkCyan)
-			btnText = fmt.Sprintf("[white::b] [[%s]] %s [-:-:-]", btn.key, btn.label)
+			btnView.SetBackgroundColor(th.Color("buttonActive"))
+			btnText = fmt.Sprintf("%s %s [-:-:-]", th.Tag("buttonActiveText"), text)
 		} else {
 			// Normal button
-			btnView.SetBackgroundColor(tcell.ColorDarkGray)
-			btnText = fmt.Sprintf("[yellow::b] [[%s]] %s [-:-:-]", btn.key, btn.label)
+			btnView.SetBackgroundColor(th.Color("button"))
+			btnText = fmt.Sprintf("%s %s [-:-:-]", label, text)
 		}
 		
+		btnView.SetMouseCapture(t.clickButton(btnView, i))
+		
 		// Add border to make it look like a button
-		btnView.SetBorder(true).
-			SetBorderPadding(0, 0, 1, 1).
-			SetBorderColor(tcell.ColorLightGray)
+		if t.layout != "compact" {
+			btnView.SetBorder(true).
+				SetBorderPadding(0, 0, 1, 1).
+				SetBorderColor(th.Color("buttonBorder"))
+		}
 		
 		btnView.SetText(btnText)
 		
 		// Add button to the row
 		t.shortcutsBox.AddItem(btnView, 0, 1, false)
 	}
+	t.buttonState = fmt.Sprint(state)
 }
 
-func (t *TUI) executeShortcut(index int) {
-	switch index {
-	case 0:
-		t.switchView("normal")
-	case 1:
-		t.switchView("debug")
-	case 2:
-		t.switchView("raw")
-	case 3:
-		t.clearCurrentView()
-	case 4:
-		t.exportLogs()
-	case 5:
-		t.app.Stop()
-	}
-}
-
-func (t *TUI) exportLogs() {
-	timestamp := time.Now().Format("20060102_150405")
-	filename := fmt.Sprintf("documentor_logs_%s.txt", timestamp)
-	
-	var content string
-	switch t.viewMode {
-	case "debug":
-		content = t.debugView.GetText(false)
-	case "raw":
-		content = t.rawView.GetText(false)
-	default:
-		content = t.mainView.GetText(false)
+// refreshShortcuts redraws the button row when a button got enabled or disabled
+func (t *TUI) refreshShortcuts() {
+	state := make([]bool, 0, len(actions))
+	for _, btn := range t.buttonActions() {
+		state = append(state, btn.enabled(t))
 	}
-	
-	if err := os.WriteFile(filename, []byte(content), 0644); err == nil {
-		t.addLog("success", fmt.Sprintf("Logs exported to %s", filename), time.Now().Format("15:04:05"))
-	} else {
-		t.addLog("error", fmt.Sprintf("Failed to export logs: %v", err), time.Now().Format("15:04:05"))
+	if fmt.Sprint(state) != t.buttonState {
+		t.updateShortcuts()
+	}
+}
+
+func (t *TUI) executeShortcut(index int) {
+	buttons := t.buttonActions()
+	if index >= 0 && index < len(buttons) {
+		t.runAction(buttons[index])
 	}
 }
 
@@ -398,12 +473,29 @@ func (t *TUI) scrollCurrentView(delta int) {
 	}
 	
 	row, col := view.GetScrollOffset()
+	_, _, _, height := view.GetInnerRect()
+	if delta > 0 && row+delta+height >= t.projections[t.viewMode].lines {
+		// Scrolled to the bottom, follow new lines again
+		view.ScrollToEnd()
+		return
+	}
 	view.ScrollTo(row+delta, col)
 }
 
 func (t *TUI) switchView(mode string) {
+	if t.selecting {
+		t.stopSelection()
+	}
 	t.viewMode = mode
+	if t.focusedWidget == "procs" || t.focusedWidget == "problems" || t.focusedWidget == "files" {
+		t.focusedWidget = "main"
+	}
 	t.pages.SwitchToPage(mode)
+	t.app.SetFocus(t.getCurrentView())
+	if mode == "debug" {
+		t.updateProcTable()
+		t.updateTrends()
+	}
 	t.updateShortcuts()
 	t.updateViewTitle()
 }
@@ -433,10 +525,10 @@ func (t *TUI) updateViewTitle() {
 	// Add scroll position indicator with visual bar
 	row, _ := view.GetScrollOffset()
 	_, _, _, height := view.GetInnerRect()
-	content := view.GetText(false)
-	lines := strings.Count(content, "\n")
+	lines := t.projections[t.viewMode].lines
 	
 	scrollBar := ""
+	t.scrollBarOffset = -1
 	if lines > height {
 		scrollPercent := 0
 		if lines > 0 {
@@ -446,9 +538,9 @@ func (t *TUI) updateViewTitle() {
 			}
 		}
 		// Create visual scroll indicator
-		barPos := scrollPercent / 10
+		barPos := scrollPercent * (scrollBarWidth - 1) / 100
 		bar := ""
-		for i := 0; i < 10; i++ {
+		for i := 0; i < scrollBarWidth; i++ {
 			if i == barPos {
 				bar += "█"
 			} else {
@@ -456,52 +548,41 @@ func (t *TUI) updateViewTitle() {
 			}
 		}
 		scrollBar = fmt.Sprintf(" [%s] %d%%", bar, scrollPercent)
+		t.scrollBarOffset = tview.TaggedStringWidth(fmt.Sprintf(" %s %s [", icon, title))
 	}
 	
 	finalTitle := fmt.Sprintf(" %s %s%s ", icon, title, scrollBar)
+	if view == t.mainView {
+		if badge := t.logFilter.Badge(t.theme); badge != "" {
+			finalTitle += badge + " "
+		}
+	}
 	view.SetTitle(finalTitle)
 }
 
 func (t *TUI) clearCurrentView() {
-	switch t.viewMode {
-	case "debug":
-		t.debugView.Clear()
-	case "raw":
-		t.rawView.Clear()
-	default:
-		t.mainView.Clear()
-	}
+	// Only hides what is on screen, the event store keeps the history
+	t.hideView(t.viewMode)
 }
 
 func (t *TUI) periodicUpdate() {
-	ticker := time.NewTicker(100 * time.Millisecond)
+	ticker := time.NewTicker(t.config.TickInterval())
 	for range ticker.C {
 		t.app.QueueUpdateDraw(func() {
-			t.spinnerIndex = (t.spinnerIndex + 1) % len(t.spinnerChars)
+			if t.animating() {
+				t.spinnerIndex = (t.spinnerIndex + 1) % len(t.spinnerChars)
+			}
+			t.checkStall()
 			t.updateStatsBox()
 			t.updateViewTitle()
+			t.refreshShortcuts()
+			if t.toast != "" && time.Now().After(t.toastUntil) {
+				t.updateFooter()
+			}
 		})
 	}
 }
 
-func (t *TUI) updateProcessStats() {
-	ticker := time.NewTicker(1 * time.Second)
-	for range ticker.C {
-		var m runtime.MemStats
-		runtime.ReadMemStats(&m)
-		
-		t.processStats.MemoryMB = int(m.Alloc / 1024 / 1024)
-		t.processStats.Goroutines = runtime.NumGoroutine()
-		// Note: Real CPU and IO stats would require platform-specific code
-		
-		if t.viewMode == "debug" {
-			t.app.QueueUpdateDraw(func() {
-				t.updateStatsBox()
-			})
-		}
-	}
-}
-
 func (t *TUI) updateInfoBox_old() {
 	// Format lock status with detailed info
 	lockIcon := ""
@@ -622,6 +703,9 @@ func (t *TUI) updateInfoBox_old() {
 
 func (t *TUI) updateStatsBox() {
 	elapsed := time.Since(t.startTime)
+	if !t.endTime.IsZero() {
+		elapsed = t.endTime.Sub(t.startTime)
+	}
 	hours := int(elapsed.Hours())
 	minutes := int(elapsed.Minutes()) % 60
 	seconds := int(elapsed.Seconds()) % 60
@@ -632,41 +716,77 @@ func (t *TUI) updateStatsBox() {
 	// Stats box - fixed layout with padding
 	timeDisplay := fmt.Sprintf("%-10s", currentTime)
 	elapsedDisplay := fmt.Sprintf("%02d:%02d:%02d", hours, minutes, seconds)
-	memDisplay := fmt.Sprintf("%-8s", fmt.Sprintf("%dMB", t.processStats.MemoryMB))
-	threadDisplay := fmt.Sprintf("%-8d", t.processStats.Goroutines)
-	
-	stats := fmt.Sprintf(
-		"[cyan] Time:   [white] %s\n"+
-		"[cyan]⏱  Elapsed:[white] %s\n"+
-		"[cyan]%s Status: [white] Working\n"+
-		"[cyan] Memory: [white] %s\n"+
-		"[cyan] Threads:[white] %s",
+	agentDisplay := t.agentSummary()
+	stateIcon, stateLabel := t.stateDisplay()
+	ownDisplay := fmt.Sprintf("%dMB, %d goroutines", t.processStats.MemoryMB, t.processStats.Goroutines)
+	
+	stats := fmt.Sprintf(t.theme.C(
+		"[label2] Time:   [text] %s\n"+
+		"[label2]⏱  Elapsed:[text] %s\n"+
+		"[label2]%s Status: [text] %s\n"+
+		"[label2] Agent:  [text] %s\n"+
+		"[label2] TUI:    [text] %s"),
 		timeDisplay,
 		elapsedDisplay,
-		t.spinnerChars[t.spinnerIndex],
-		memDisplay,
-		threadDisplay,
+		stateIcon,
+		stateLabel,
+		agentDisplay,
+		ownDisplay,
 	)
 	
 	t.statsBox.SetText(stats)
+	t.updateStatusLine()
+}
+
+// showToast shows a notice in the footer for a few seconds
+func (t *TUI) showToast(text string) {
+	t.toast = text
+	t.toastUntil = time.Now().Add(t.config.ToastDuration())
+	t.updateFooter()
 }
 
 func (t *TUI) updateFooter() {
-	status := "[gray] Ready - Waiting for input[white]"
+	defer t.updateStatusLine()
+	if notice := t.footerNotice(); notice != "" {
+		t.footerBox.SetText(notice)
+		return
+	}
+	
+	status := t.theme.C("[muted] Ready - Waiting for input[text]")
 	if t.files.Current != "" {
-		// Truncate long file paths
-		file := t.files.Current
-		if len(file) > 70 {
-			file = "..." + file[len(file)-67:]
+		// Shorten long file paths to the footer width
+		width := 70
+		if _, _, w, _ := t.footerBox.GetInnerRect(); w > 0 {
+			width = w - runewidth.StringWidth(" Processing: ")
 		}
-		status = fmt.Sprintf("[green] Processing:[white] [yellow]%s[white]", file)
+		file := tview.Escape(shortenPath(t.files.Current, width))
+		status = fmt.Sprintf(t.theme.C("[success] Processing:[text] [label]%s[text]"), file)
 	}
 	t.footerBox.SetText(status)
 }
 
+// footerNotice returns the toast or the selection hint, if either is showing
+func (t *TUI) footerNotice() string {
+	if t.toast != "" {
+		if time.Now().Before(t.toastUntil) {
+			return fmt.Sprintf(t.theme.C("[toast] %s [-:-:-]"), tview.Escape(t.toast))
+		}
+		t.toast = ""
+	}
+	if waiting := len(t.approvals.queue); waiting > 0 {
+		return fmt.Sprintf(t.theme.C("[warning]\uf023 %d write approval(s) waiting[text], press %s"), waiting, t.keyHint("approvals"))
+	}
+	if t.selecting {
+		return t.theme.C("[accent] SELECT[text]  [label]j/k[text] move  [label]Space[text] mark a range  [label]y[text] copy  [label]Esc[text] leave")
+	}
+	return ""
+}
+
 func (t *TUI) handleMessage(msg Message) {
 	t.app.QueueUpdateDraw(func() {
 		t.lastUpdate = time.Now()
+		t.history.messageCount++
+		t.tally(msg)
 		
 		// Update state
 		if msg.Phase.Name != "" {
@@ -684,6 +804,10 @@ func (t *TUI) handleMessage(msg Message) {
 		}
 		if msg.LockInfo.Status != "" {
 			t.lockInfo = msg.LockInfo
+			// A launched agent or --agent-pid is more reliable than the lock file
+			if t.agentCmd == nil && !t.fixedPID && msg.LockInfo.Status == "locked" && msg.LockInfo.PID > 0 {
+				t.setAgentPID(msg.LockInfo.PID)
+			}
 			t.updateInfoBox()
 		}
 		
@@ -695,31 +819,42 @@ func (t *TUI) handleMessage(msg Message) {
 		
 		// Add to appropriate view
 		switch msg.Type {
-		case "log":
-			t.addLog(msg.Level, msg.Content, timestamp)
-		case "tool":
-			t.addToolCall(msg.Tool, msg.Content, timestamp)
-		case "debug":
-			t.addDebug(msg.Content, timestamp)
-		case "raw":
-			t.addRaw(msg.Content, timestamp)
+		case "log", "tool", "debug", "raw":
+			t.record(eventFromMessage(msg, timestamp))
 		case "phase":
 			t.phase = msg.Phase
 			t.updateInfoBox()
+			t.record(eventFromMessage(msg, timestamp))
 		case "file":
 			t.files = msg.Files
 			t.updateInfoBox()
 			t.updateFooter()
+			t.record(eventFromMessage(msg, timestamp))
+		case "plan":
+			t.record(eventFromMessage(msg, timestamp))
+		case "project", "lockInfo":
+			// State already applied above, keep it out of the logs view
+			t.record(eventFromMessage(msg, timestamp))
+		case "summary":
+			t.record(eventFromMessage(msg, timestamp))
+			if msg.Summary != nil {
+				t.noteSummary(*msg.Summary)
+			}
 		case "memory":
 			if memMB, ok := msg.Data.(float64); ok {
-				t.processStats.MemoryMB = int(memMB)
+				t.agentStats.ReportedMB = int(memMB)
 			}
 		case "password_request":
+			t.record(eventFromMessage(msg, timestamp))
 			// Handle password request
 			var req PasswordRequest
 			if jsonData, err := json.Marshal(msg); err == nil {
 				if err := json.Unmarshal(jsonData, &req); err == nil {
 					t.showSimplePasswordModal(req.Prompt, req.Context, func(password string, cancelled bool) {
+						if t.state == StateAwaitingInput {
+							t.setState(StateWorking, "password answered")
+						}
+						t.reply(PasswordResponse{Type: "password_response", RequestID: req.RequestID, Password: password, Cancelled: cancelled})
 						if cancelled {
 							t.addLog("info", "Password cancelled", time.Now().Format("15:04:05"))
 						} else {
@@ -728,78 +863,105 @@ func (t *TUI) handleMessage(msg Message) {
 					})
 				}
 			}
+		case "approval_request":
+			t.record(eventFromMessage(msg, timestamp))
+			var req ApprovalRequest
+			if err := json.Unmarshal([]byte(msg.raw), &req); err == nil && req.Path != "" {
+				t.noteApproval(&req)
+			} else {
+				t.addLog("error", "Malformed approval request, the agent is not answered", timestamp)
+			}
 		default:
-			t.addLog("info", msg.Content, timestamp)
+			t.record(eventFromMessage(msg, timestamp))
 		}
+		t.noteFiles(msg)
+		t.noteChange(msg)
+		t.noteMessage(msg)
 	})
 }
 
 func (t *TUI) addLog(level, content, timestamp string) {
-	color := "white"
+	t.record(Event{Type: "log", Level: level, Content: content, Timestamp: timestamp})
+}
+
+func (t *TUI) addToolCall(tool, content, timestamp string) {
+	t.record(Event{Type: "tool", Tool: tool, Content: content, Timestamp: timestamp})
+}
+
+func (t *TUI) addDebug(content, timestamp string) {
+	t.record(Event{Type: "debug", Content: content, Timestamp: timestamp})
+}
+
+func (t *TUI) addRaw(content, timestamp string) {
+	t.record(Event{Type: "raw", Content: content, Timestamp: timestamp})
+}
+
+// formatLogEntry renders a logs view entry with its level color and icon
+func (t *TUI) formatLogEntry(e Event) string {
+	if e.Type == "tool" {
+		return fmt.Sprintf(t.theme.C("[muted]%s[text] [tool] %s:[text] %s\n"),
+			e.Timestamp, e.Tool, e.Content)
+	}
+	
+	role := "text"
 	icon := ""
 	
-	switch level {
+	switch e.Level {
 	case "error":
-		color = "red"
+		role = "error"
 		icon = ""
 	case "warning":
-		color = "yellow"
+		role = "warning"
 		icon = ""
 	case "success":
-		color = "green"
+		role = "success"
 		icon = ""
 	case "info":
-		color = "cyan"
+		role = "info"
 		icon = ""
 	}
 	
-	line := fmt.Sprintf("[gray]%s[white] [%s]%s %s[white]\n", 
-		timestamp, color, icon, content)
-	
-	fmt.Fprint(t.mainView, line)
-	t.mainView.ScrollToEnd()
-}
-
-func (t *TUI) addToolCall(tool, content, timestamp string) {
-	line := fmt.Sprintf("[gray]%s[white] [yellow] %s:[white] %s\n",
-		timestamp, tool, content)
-	
-	fmt.Fprint(t.mainView, line)
-	fmt.Fprint(t.debugView, line)
-	t.mainView.ScrollToEnd()
-	t.debugView.ScrollToEnd()
+	return fmt.Sprintf(t.theme.C("[muted]%s[text] %s%s %s[text]\n"),
+		e.Timestamp, t.theme.Tag(role), icon, e.Content)
 }
 
-func (t *TUI) addDebug(content, timestamp string) {
-	line := fmt.Sprintf("[gray]%s[white] [dim] %s[white]\n",
-		timestamp, content)
-	fmt.Fprint(t.debugView, line)
-	t.debugView.ScrollToEnd()
+// formatDebugEntry renders a debug view entry; tool calls look as in the logs view
+func (t *TUI) formatDebugEntry(e Event) string {
+	if e.Type == "tool" {
+		return t.formatLogEntry(e)
+	}
+	return fmt.Sprintf(t.theme.C("[muted]%s[text] [dim] %s[text]\n"),
+		e.Timestamp, e.Content)
 }
 
-func (t *TUI) addRaw(content, timestamp string) {
-	line := fmt.Sprintf("[gray]%s[white] [dim][white] %s\n",
-		timestamp, content)
-	fmt.Fprint(t.rawView, line)
-	t.rawView.ScrollToEnd()
+// formatRawEntry renders a raw API view entry
+func (t *TUI) formatRawEntry(e Event) string {
+	return fmt.Sprintf(t.theme.C("[muted]%s[text] [dim][text] %s\n"),
+		e.Timestamp, e.Content)
 }
 
 func (t *TUI) Run() error {
-	// Start stdin reader in background
-	go t.readStdin()
+	// Start message reader in background
+	go t.readMessages()
+	go t.waitAgent()
+	
+	if err := t.newFocusScreen(); err != nil {
+		return err
+	}
 	
 	// Run the app
 	return t.app.Run()
 }
 
-func (t *TUI) readStdin() {
-	scanner := bufio.NewScanner(os.Stdin)
+func (t *TUI) readMessages() {
+	scanner := bufio.NewScanner(t.input)
 	for scanner.Scan() {
 		line := scanner.Text()
 		
 		// Try to parse as JSON
 		var msg Message
 		if err := json.Unmarshal([]byte(line), &msg); err == nil {
+			msg.raw = line
 			t.handleMessage(msg)
 		} else {
 			// Plain text message
@@ -810,12 +972,108 @@ func (t *TUI) readStdin() {
 			})
 		}
 	}
+	t.app.QueueUpdateDraw(t.inputClosed)
 }
 
 func main() {
-	tui := NewTUI()
+	configPath := flag.String("config", "", "config file (default ~/.config/documentor/tui.json)")
+	scrollbackLines := flag.Int("scrollback", 0, "maximum lines kept per view (overrides config)")
+	history := flag.Int("history", 0, "maximum events kept in memory (overrides config)")
+	spill := flag.String("spill", "", "append events dropped from memory to this NDJSON file (overrides config)")
+	noClipboard := flag.Bool("no-clipboard", false, "write copies to a temp file instead of the terminal clipboard (overrides config)")
+	noMouse := flag.Bool("no-mouse", false, "leave the mouse to the terminal for native text selection (overrides config)")
+	replies := flag.String("replies", "", "write answers to the agent to this FIFO (default: the launched command's stdin)")
+	agentPID := flag.Int("agent-pid", 0, "PID of the agent to monitor (default: the launched command or the lock file PID)")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: documentor-tui [flags] [-- command args...]")
+		fmt.Fprintln(os.Stderr, "       documentor-tui [--config FILE] config dump")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	
+	cfg, sources, errs := LoadConfig(*configPath)
+	
+	// Flags win over config files
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "scrollback":
+			cfg.Scrollback.Lines = *scrollbackLines
+		case "history":
+			cfg.Scrollback.History = *history
+		case "spill":
+			cfg.Scrollback.SpillFile = *spill
+		case "no-clipboard":
+			if *noClipboard {
+				cfg.Clipboard = "file"
+			}
+		case "no-mouse":
+			cfg.Mouse = !*noMouse
+		}
+	})
+	errs = append(errs, cfg.Validate()...)
+	
+	// Everything after "--" is the agent to launch
+	var command []string
+	for i, arg := range os.Args {
+		if arg == "--" {
+			command = os.Args[i+1:]
+			break
+		}
+	}
+	
+	if command == nil && flag.Arg(0) == "config" {
+		if flag.Arg(1) != "dump" {
+			fmt.Fprintln(os.Stderr, "Usage: documentor-tui [--config FILE] config dump")
+			os.Exit(2)
+		}
+		os.Exit(dumpConfig(cfg, sources, errs))
+	}
+	
+	if *replies != "" {
+		if err := checkRepliesFIFO(*replies); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -replies: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	tui := NewTUI(cfg)
+	tui.input = os.Stdin
+	if *agentPID > 0 {
+		tui.setAgentPID(*agentPID)
+		tui.fixedPID = true
+	}
+	if len(command) > 0 {
+		cmd, output, answers, err := startAgent(command, *replies)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: cannot start %s: %v\n", command[0], err)
+			os.Exit(1)
+		}
+		tui.agentCmd = cmd
+		tui.agentStatus.Store(-1)
+		tui.input = output
+		tui.setAgentPID(cmd.Process.Pid)
+		if *replies == "" {
+			tui.startReplies(func() (io.Writer, error) { return answers, nil })
+		}
+	}
+	if *replies != "" {
+		tui.startReplies(openRepliesFile(*replies))
+	}
+	
+	// Show config problems once the UI is up
+	for _, err := range errs {
+		tui.addLog("error", fmt.Sprintf("Config: %v", err), time.Now().Format("15:04:05"))
+	}
+	if len(errs) > 0 {
+		tui.showToast(fmt.Sprintf("%d config problem(s), using defaults for those settings", len(errs)))
+	}
+	
 	if err := tui.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
-}
\ No newline at end of file
+	// A launched agent's status is ours, so scripts see how the run went
+	if status := tui.agentStatus.Load(); status > 0 {
+		os.Exit(int(status))
+	}
+}
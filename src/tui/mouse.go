@@ -0,0 +1,137 @@
+package main
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// scrollBarWidth is the number of cells of the scroll indicator in view titles
+const scrollBarWidth = 10
+
+// setupMouse turns the mouse on if the config allows it
+func (t *TUI) setupMouse() {
+	t.app.EnableMouse(t.config.Mouse)
+	t.app.SetMouseCapture(t.handleMouse)
+}
+
+// toggleMouse switches between TUI mouse handling and native terminal selection
+func (t *TUI) toggleMouse() {
+	t.config.Mouse = !t.config.Mouse
+	t.app.EnableMouse(t.config.Mouse)
+	if t.config.Mouse {
+		t.showToast("Mouse on")
+	} else {
+		t.showToast("Mouse off, the terminal selects text again")
+	}
+}
+
+// handleMouse keeps the focus state in step with clicks, ignores clicks on
+// the panels that only show information and drags the scroll indicator
+func (t *TUI) handleMouse(event *tcell.EventMouse, action tview.MouseAction) (*tcell.EventMouse, tview.MouseAction) {
+	if t.modalOpen {
+		return event, action
+	}
+	x, y := event.Position()
+
+	if t.draggingScroll {
+		if event.Buttons()&tcell.Button1 == 0 {
+			t.draggingScroll = false
+		} else {
+			t.dragScrollBar(x)
+		}
+		return nil, action
+	}
+
+	for _, info := range t.infoPanels() {
+		if info.InRect(x, y) {
+			// These panels only show information
+			return nil, action
+		}
+	}
+	if action != tview.MouseLeftDown {
+		return event, action
+	}
+	view := t.currentTextView()
+	if t.onScrollBar(view, x, y) {
+		t.draggingScroll = true
+		t.dragScrollBar(x)
+		return nil, action
+	}
+
+	focus := ""
+	switch {
+	case t.viewMode == "normal" && t.problemsVisible() && t.problemsTree.InRect(x, y):
+		focus = "problems"
+	case t.viewMode == "normal" && t.filesVisible() && t.filesTree.InRect(x, y):
+		focus = "files"
+	case t.viewMode == "debug" && t.layout != "minimal" && t.procTable.InRect(x, y):
+		focus = "procs"
+	case view.InRect(x, y):
+		focus = "main"
+	default:
+		// The buttons handle their own clicks
+		return event, action
+	}
+	if focus != t.focusedWidget {
+		t.focusedWidget = focus
+		t.updateShortcuts()
+	}
+	return event, action
+}
+
+// clickButton runs a button of the button row when it is clicked
+func (t *TUI) clickButton(button *tview.TextView, index int) func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+	return func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+		// The row offers every event to every button
+		if !button.InRect(event.Position()) {
+			return action, event
+		}
+		switch action {
+		case tview.MouseLeftClick:
+			t.selectedBtn = index
+			t.executeShortcut(index)
+			return action, nil
+		case tview.MouseLeftDown:
+			// Don't let the button take focus from the view
+			return action, nil
+		}
+		return action, event
+	}
+}
+
+// scrollBarCell returns which cell of the scroll indicator in the title of
+// a view is at column x, which may be outside of the indicator
+func (t *TUI) scrollBarCell(view *tview.TextView, x int) int {
+	rectX, _, _, _ := view.GetRect()
+	// Box draws the title one cell in from the left corner
+	return x - (rectX + 1 + t.scrollBarOffset)
+}
+
+// onScrollBar reports whether a position is on the scroll indicator
+func (t *TUI) onScrollBar(view *tview.TextView, x, y int) bool {
+	if t.scrollBarOffset < 0 {
+		return false
+	}
+	_, rectY, _, _ := view.GetRect()
+	cell := t.scrollBarCell(view, x)
+	return y == rectY && cell >= 0 && cell < scrollBarWidth
+}
+
+// dragScrollBar scrolls the current view to the position of the mouse on
+// the scroll indicator; past its right end the view follows new lines again
+func (t *TUI) dragScrollBar(x int) {
+	view := t.currentTextView()
+	col := t.scrollBarCell(view, x)
+	if col < 0 {
+		col = 0
+	}
+	if col >= scrollBarWidth {
+		view.ScrollToEnd()
+		t.updateViewTitle()
+		return
+	}
+	_, _, _, height := view.GetInnerRect()
+	lines := t.projections[t.viewMode].lines
+	view.ScrollTo(col*(lines-height)/(scrollBarWidth-1), 0)
+	t.updateViewTitle()
+}
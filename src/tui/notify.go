@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// focusScreen is the terminal screen with focus reporting turned on. tview
+// ignores focus events, so they are taken out of the event stream here.
+type focusScreen struct {
+	tcell.Screen
+	focused atomic.Bool
+}
+
+func (s *focusScreen) PollEvent() tcell.Event {
+	for {
+		event := s.Screen.PollEvent()
+		focus, ok := event.(*tcell.EventFocus)
+		if !ok {
+			return event
+		}
+		s.focused.Store(focus.Focused)
+	}
+}
+
+// newFocusScreen sets up the terminal screen for the app
+func (t *TUI) newFocusScreen() error {
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		return err
+	}
+	t.screen = &focusScreen{Screen: screen}
+	t.app.SetScreen(t.screen)
+	t.screen.EnableFocus()
+	return nil
+}
+
+// terminalFocused reports whether the terminal window has focus. Terminals
+// that don't report focus count as unfocused, so notifications still go out.
+func (t *TUI) terminalFocused() bool {
+	return t.screen != nil && t.screen.focused.Load()
+}
+
+// notification is one event worth telling the user about
+type notification struct {
+	event string // completed, failed, stalled or input
+	title string
+	body  string
+}
+
+// notifyState sends the notifications configured for a new run state
+func (t *TUI) notifyState(state RunState, reason string) {
+	name := filepath.Base(t.projectPath)
+	if t.projectPath == "No project loaded" {
+		name = "docuMentor"
+	}
+	n := notification{title: "docuMentor: " + name}
+	switch state {
+	case StateCompleted:
+		n.event = "completed"
+		n.body = "Run completed in " + formatElapsed(t.endTime.Sub(t.startTime))
+	case StateFailed:
+		n.event = "failed"
+		n.body = "Run failed" + reasonSuffix(reason)
+	case StateDisconnected:
+		n.event = "failed"
+		n.body = "Input closed before the run reported a result"
+	case StateStalled:
+		n.event = "stalled"
+		n.body = fmt.Sprintf("No message for %ds", t.config.Monitor.StallSeconds)
+	case StateAwaitingInput:
+		n.event = "input"
+		n.body = "Waiting for your input" + reasonSuffix(reason)
+	default:
+		return
+	}
+	t.notify(n)
+}
+
+// notify rings the bell, shows a desktop notification and runs the
+// configured command, as far as the event's settings allow
+func (t *TUI) notify(n notification) {
+	cfg := t.config.Notifications
+	if !cfg.WhenFocused && t.terminalFocused() {
+		return
+	}
+	event := cfg.Events.get(n.event)
+	if event.Bell && t.screen != nil {
+		t.screen.Beep()
+	}
+	if event.Desktop {
+		t.writeTerminal(desktopSequence(cfg.OSC, n.title, n.body))
+	}
+	if event.Command && len(cfg.Command) > 0 {
+		t.runNotifyCommand(cfg.Command, n)
+	}
+}
+
+// desktopSequence returns the OSC escape sequence of a desktop notification:
+// OSC 9 (iTerm2, kitty, WezTerm, Windows Terminal) or OSC 777 (foot, urxvt,
+// Konsole, VTE). "auto" picks OSC 777 on the terminals that only know that one.
+func desktopSequence(osc, title, body string) string {
+	clean := func(s string) string {
+		// Control characters and ";" would end the sequence or a field early
+		s = strings.Map(func(r rune) rune {
+			if r < 0x20 || r == 0x7f {
+				return ' '
+			}
+			return r
+		}, s)
+		return strings.ReplaceAll(s, ";", ",")
+	}
+	if osc == "auto" {
+		osc = "9"
+		term := os.Getenv("TERM")
+		if strings.HasPrefix(term, "foot") || strings.HasPrefix(term, "rxvt") ||
+			os.Getenv("KONSOLE_VERSION") != "" || os.Getenv("VTE_VERSION") != "" {
+			osc = "777"
+		}
+	}
+	seq := fmt.Sprintf("\x1b]9;%s: %s\x07", clean(title), clean(body))
+	if osc == "777" {
+		seq = fmt.Sprintf("\x1b]777;notify;%s;%s\x07", clean(title), clean(body))
+	}
+	return passthrough(seq)
+}
+
+// passthrough wraps an escape sequence so tmux hands it to the outer
+// terminal, which it does with allow-passthrough enabled
+func passthrough(seq string) string {
+	if os.Getenv("TMUX") == "" {
+		return seq
+	}
+	return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+}
+
+// writeTerminal writes an escape sequence straight to the terminal. It runs
+// on the event loop, between draws, so it never lands inside one.
+func (t *TUI) writeTerminal(seq string) {
+	if t.screen == nil {
+		return
+	}
+	if tty, ok := t.screen.Tty(); ok {
+		tty.Write([]byte(seq))
+	}
+}
+
+// runNotifyCommand starts the configured command without a shell, with
+// {event}, {title} and {body} filled in, and logs its failure to the debug view
+func (t *TUI) runNotifyCommand(command []string, n notification) {
+	replacer := strings.NewReplacer("{event}", n.event, "{title}", n.title, "{body}", n.body)
+	args := make([]string, len(command))
+	for i, arg := range command {
+		args[i] = replacer.Replace(arg)
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	if err := cmd.Start(); err != nil {
+		t.addDebug(fmt.Sprintf("Notification command failed: %v", err), time.Now().Format("15:04:05"))
+		return
+	}
+	go func() {
+		if err := cmd.Wait(); err != nil {
+			t.app.QueueUpdateDraw(func() {
+				t.addDebug(fmt.Sprintf("Notification command failed: %v", err), time.Now().Format("15:04:05"))
+			})
+		}
+	}()
+}
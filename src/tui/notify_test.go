@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+func TestDesktopSequence(t *testing.T) {
+	tests := []struct {
+		name             string
+		osc, title, body string
+		term, konsole    string
+		tmux             string
+		want             string
+	}{
+		{"osc 9", "9", "docuMentor", "Run completed", "", "", "", "\x1b]9;docuMentor: Run completed\x07"},
+		{"osc 777", "777", "docuMentor", "Run completed", "", "", "", "\x1b]777;notify;docuMentor;Run completed\x07"},
+		{"auto", "auto", "t", "b", "xterm-kitty", "", "", "\x1b]9;t: b\x07"},
+		{"auto on foot", "auto", "t", "b", "foot", "", "", "\x1b]777;notify;t;b\x07"},
+		{"auto on konsole", "auto", "t", "b", "xterm-256color", "220400", "", "\x1b]777;notify;t;b\x07"},
+		{"separators", "777", "a;b", "c;d", "", "", "", "\x1b]777;notify;a,b;c,d\x07"},
+		{"control characters", "9", "t", "line\none\x07\x1b", "", "", "", "\x1b]9;t: line one  \x07"},
+		{"tmux", "9", "t", "b", "", "", "/tmp/tmux-0/default,1,0", "\x1bPtmux;\x1b\x1b]9;t: b\x07\x1b\\"},
+		{"tmux 777", "777", "t", "b", "", "", "/tmp/tmux-0/default,1,0", "\x1bPtmux;\x1b\x1b]777;notify;t;b\x07\x1b\\"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TERM", tt.term)
+			t.Setenv("KONSOLE_VERSION", tt.konsole)
+			t.Setenv("VTE_VERSION", "")
+			t.Setenv("TMUX", tt.tmux)
+			if got := desktopSequence(tt.osc, tt.title, tt.body); got != tt.want {
+				t.Errorf("desktopSequence = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
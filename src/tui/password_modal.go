@@ -1,3 +1,7 @@
+//go:build ignore
+
+// Superseded by password_modal_simple.go; kept for reference only.
+
 package main
 
 import (
@@ -64,7 +64,7 @@ func (t *TUI) showSimplePasswordModal(prompt, context string, onSubmit func(stri
 	textView := tview.NewTextView().
 		SetDynamicColors(true).
 		SetTextAlign(tview.AlignCenter).
-		SetText(fmt.Sprintf("[yellow]%s[white]\n\n[gray]%s[white]\n\n[dim]Press Enter to submit, Escape to cancel[white]", prompt, context))
+		SetText(fmt.Sprintf(t.theme.C("[warning]%s[text]\n\n[muted]%s[text]\n\n[dim]Press Enter to submit, Escape to cancel[text]"), prompt, context))
 	
 	// Create container without border
 	container := tview.NewFlex().
@@ -89,10 +89,19 @@ func (t *TUI) showSimplePasswordModal(prompt, context string, onSubmit func(stri
 func (t *TUI) getCurrentView() tview.Primitive {
 	switch t.viewMode {
 	case "debug":
+		if t.focusedWidget == "procs" {
+			return t.procTable
+		}
 		return t.debugView
 	case "raw":
 		return t.rawView
 	default:
+		if t.focusedWidget == "problems" {
+			return t.problemsTree
+		}
+		if t.focusedWidget == "files" {
+			return t.filesTree
+		}
 		return t.mainView
 	}
 }
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// maxPreviewBytes is how much of a file the preview reads
+const maxPreviewBytes = 1 << 20
+
+// previewPoll is how often an open preview checks its file for changes
+const previewPoll = time.Second
+
+// preview is the open preview page
+type preview struct {
+	path        string
+	frontmatter *tview.TextView
+	body        *tview.TextView
+	page        *tview.Flex
+	modTime     time.Time
+	size        int64
+	done        chan struct{} // closed when the preview closes
+}
+
+// previewTarget returns the file to preview: the selected file of the file
+// tree, the file of the highlighted log line, or the last doc written
+func (t *TUI) previewTarget() string {
+	if t.focusedWidget == "files" {
+		if node := t.filesTree.GetCurrentNode(); node != nil {
+			if path, ok := node.GetReference().(string); ok && !strings.HasSuffix(path, "/") {
+				return path
+			}
+		}
+	}
+	seqs := t.highlightedSeqs()
+	for i := len(seqs) - 1; i >= 0; i-- {
+		if e, ok := t.events.Find(seqs[i]); ok && e.Type == "tool" && looksLikePath(e.Content) {
+			return e.Content
+		}
+	}
+	return t.fileSet.lastWritten
+}
+
+// previewSelected opens the preview of previewTarget
+func (t *TUI) previewSelected() {
+	path := t.previewTarget()
+	if path == "" {
+		t.showToast("Select a file in the file tree or a tool line in the logs first; no doc written yet")
+		return
+	}
+	t.showPreview(path)
+}
+
+// resolvePath makes a reported path absolute, relative ones being relative
+// to the project
+func (t *TUI) resolvePath(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	if !filepath.IsAbs(path) && filepath.IsAbs(t.projectPath) {
+		path = filepath.Join(t.projectPath, path)
+	}
+	return path
+}
+
+// showPreview opens a full-screen preview of a file that follows changes
+// to it until it is closed
+func (t *TUI) showPreview(path string) {
+	p := &preview{
+		path: t.resolvePath(path),
+		frontmatter: tview.NewTextView().
+			SetDynamicColors(true).
+			SetWrap(true),
+		body: tview.NewTextView().
+			SetDynamicColors(true).
+			SetScrollable(true).
+			SetWrap(true).
+			SetWordWrap(true),
+		done: make(chan struct{}),
+	}
+	p.frontmatter.SetBorder(true).
+		SetBorderPadding(0, 0, 1, 1).
+		SetTitle(" frontmatter ").
+		SetTitleAlign(tview.AlignLeft)
+	p.body.SetBorder(true).
+		SetBorderPadding(0, 0, 1, 1).
+		SetTitleAlign(tview.AlignLeft)
+
+	footer := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(t.theme.C("[label]↑/↓ PgUp/PgDn[text] scroll   [label]R[text] reload   [label]Esc[text] back to the logs   [label]Q[text] quit"))
+	footer.SetBorder(true).
+		SetBorderPadding(0, 0, 1, 1)
+
+	p.page = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(p.frontmatter, 0, 0, false).
+		AddItem(p.body, 0, 1, true).
+		AddItem(footer, 3, 0, false)
+
+	closePreview := func() {
+		close(p.done)
+		t.modalOpen = false
+		t.app.SetRoot(t.rootPages, true)
+		t.app.SetFocus(t.getCurrentView())
+	}
+	p.body.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc || event.Rune() == 'o' || event.Rune() == 'O':
+			closePreview()
+			return nil
+		case event.Rune() == 'q' || event.Rune() == 'Q':
+			close(p.done)
+			t.app.Stop()
+			return nil
+		case event.Rune() == 'r' || event.Rune() == 'R':
+			t.loadPreview(p)
+			return nil
+		}
+		return event
+	})
+
+	t.loadPreview(p)
+	t.modalOpen = true
+	t.app.SetRoot(p.page, true)
+	t.app.SetFocus(p.body)
+	go t.watchPreview(p)
+}
+
+// watchPreview reloads the preview when its file changes
+func (t *TUI) watchPreview(p *preview) {
+	ticker := time.NewTicker(previewPoll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			info, err := os.Stat(p.path)
+			if err != nil || (info.ModTime().Equal(p.modTime) && info.Size() == p.size) {
+				continue
+			}
+			t.app.QueueUpdateDraw(func() {
+				select {
+				case <-p.done:
+				default:
+					t.loadPreview(p)
+				}
+			})
+		}
+	}
+}
+
+// loadPreview reads the file and renders it, keeping the scroll position
+func (t *TUI) loadPreview(p *preview) {
+	th := t.theme
+	title := fmt.Sprintf(" \uf15c %s ", tview.Escape(p.path))
+
+	info, err := os.Stat(p.path)
+	var data []byte
+	if err == nil {
+		data, err = readHead(p.path, maxPreviewBytes)
+	}
+	if err != nil {
+		p.modTime, p.size = time.Time{}, 0
+		p.page.ResizeItem(p.frontmatter, 0, 0)
+		p.body.SetTitle(title)
+		p.body.SetText(fmt.Sprintf(th.C("[error]%s[text]\n\n[muted]The preview reloads once the file can be read.[text]"),
+			tview.Escape(err.Error())))
+		return
+	}
+	p.modTime, p.size = info.ModTime(), info.Size()
+
+	front, body := splitFrontmatter(string(data))
+	if front == "" {
+		p.page.ResizeItem(p.frontmatter, 0, 0)
+	} else {
+		lines := strings.Count(front, "\n") + 1
+		p.page.ResizeItem(p.frontmatter, min(lines, 12)+2, 0)
+		p.frontmatter.SetText(t.renderFrontmatter(front))
+	}
+	if int64(len(data)) < info.Size() {
+		body += fmt.Sprintf("\n\n… %d more bytes not shown", info.Size()-int64(len(data)))
+	}
+
+	row, col := p.body.GetScrollOffset()
+	p.body.SetTitle(title + th.C("[muted]updated "+info.ModTime().Format("15:04:05")+"[text] "))
+	p.body.SetText(t.renderMarkdown(body))
+	p.body.ScrollTo(row, col)
+}
+
+// readHead reads at most limit bytes of a file
+func readHead(path string, limit int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(io.LimitReader(f, limit))
+}
+
+// splitFrontmatter separates a leading YAML frontmatter block from the
+// Markdown body
+func splitFrontmatter(text string) (front, body string) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if !strings.HasPrefix(text, "---\n") {
+		return "", text
+	}
+	rest := text[len("---\n"):]
+	end := strings.Index(rest, "\n---")
+	if end < 0 {
+		return "", text
+	}
+	front = rest[:end]
+	body = strings.TrimPrefix(rest[end+len("\n---"):], "\n")
+	return front, body
+}
+
+// frontmatterKey matches the key of a YAML mapping line
+var frontmatterKey = regexp.MustCompile(`^(\s*-?\s*)([\w.-]+):`)
+
+// renderFrontmatter colors the keys of the frontmatter
+func (t *TUI) renderFrontmatter(front string) string {
+	th := t.theme
+	lines := strings.Split(front, "\n")
+	for i, line := range lines {
+		if m := frontmatterKey.FindStringSubmatchIndex(line); m != nil {
+			lines[i] = tview.Escape(line[:m[4]]) + th.Tag("label") + line[m[4]:m[5]] + th.Tag("text") + ":" + tview.Escape(line[m[1]:])
+		} else {
+			lines[i] = tview.Escape(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Block level Markdown
+var (
+	mdHeading = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	mdList    = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	mdQuote   = regexp.MustCompile(`^\s*>\s?(.*)$`)
+	mdRule    = regexp.MustCompile(`^\s*([-*_])(\s*[-*_]){2,}\s*$`)
+)
+
+// mdInline matches inline code, wiki links, links, bold and italic text
+var mdInline = regexp.MustCompile("`([^`]+)`|\\[\\[([^\\]]+)\\]\\]|\\[([^\\]]+)\\]\\(([^)\\s]+)\\)|\\*\\*([^*]+)\\*\\*|__([^_]+)__|\\*([^*\\s][^*]*)\\*|\\b_([^_\\s][^_]*)_\\b")
+
+// renderMarkdown turns Markdown into tview tags: headings, lists, quotes,
+// rules and code blocks, plus inline code, links and emphasis
+func (t *TUI) renderMarkdown(text string) string {
+	th := t.theme
+	var out strings.Builder
+	inCode := false
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inCode = !inCode
+			if lang := strings.Trim(trimmed, "`~ "); inCode && lang != "" {
+				out.WriteString(th.Tag("muted") + tview.Escape(lang) + th.Tag("text") + "\n")
+			}
+			continue
+		}
+		switch {
+		case inCode:
+			out.WriteString(th.Tag("tool") + "  " + tview.Escape(line) + th.Tag("text"))
+		case mdHeading.MatchString(line):
+			m := mdHeading.FindStringSubmatch(line)
+			text := m[2]
+			if len(m[1]) == 1 {
+				// Before rendering: upper case tags are not tags
+				text = upperText(text)
+			}
+			heading := t.renderInline(text)
+			out.WriteString(th.Tag("accent") + "[::b]" + heading + "[::-]" + th.Tag("text"))
+		case mdRule.MatchString(line):
+			out.WriteString(th.Tag("muted") + strings.Repeat("─", 40) + th.Tag("text"))
+		case mdList.MatchString(line):
+			m := mdList.FindStringSubmatch(line)
+			bullet := "•"
+			if m[2][0] >= '0' && m[2][0] <= '9' {
+				bullet = m[2]
+			}
+			out.WriteString(m[1] + th.Tag("accent") + bullet + th.Tag("text") + " " + t.renderInline(m[3]))
+		case mdQuote.MatchString(line):
+			m := mdQuote.FindStringSubmatch(line)
+			out.WriteString(th.Tag("muted") + "│ " + t.renderInline(m[1]) + th.Tag("text"))
+		default:
+			out.WriteString(t.renderInline(line))
+		}
+		out.WriteString("\n")
+	}
+	return out.String()
+}
+
+// upperText upper cases the plain text of Markdown, leaving inline code,
+// wiki links and link targets as written
+func upperText(text string) string {
+	var out strings.Builder
+	last := 0
+	for _, m := range mdInline.FindAllStringSubmatchIndex(text, -1) {
+		out.WriteString(strings.ToUpper(text[last:m[0]]))
+		switch {
+		case m[2] >= 0, m[4] >= 0:
+			out.WriteString(text[m[0]:m[1]])
+		case m[6] >= 0:
+			// Only the label of [label](target)
+			out.WriteString(text[m[0]:m[6]] + strings.ToUpper(text[m[6]:m[7]]) + text[m[7]:m[1]])
+		default:
+			out.WriteString(strings.ToUpper(text[m[0]:m[1]]))
+		}
+		last = m[1]
+	}
+	out.WriteString(strings.ToUpper(text[last:]))
+	return out.String()
+}
+
+// renderInline renders the inline Markdown of one line, escaping the rest
+func (t *TUI) renderInline(line string) string {
+	th := t.theme
+	var out strings.Builder
+	last := 0
+	for _, m := range mdInline.FindAllStringSubmatchIndex(line, -1) {
+		out.WriteString(tview.Escape(line[last:m[0]]))
+		last = m[1]
+		group := func(i int) string { return tview.Escape(line[m[2*i]:m[2*i+1]]) }
+		switch {
+		case m[2] >= 0:
+			out.WriteString(th.Tag("tool") + group(1) + th.Tag("text"))
+		case m[4] >= 0:
+			out.WriteString(th.Tag("info") + tview.Escape(line[m[0]:m[1]]) + th.Tag("text"))
+		case m[6] >= 0:
+			out.WriteString(th.Tag("info") + "[::u]" + group(3) + "[::-]" + th.Tag("muted") + " (" + group(4) + ")" + th.Tag("text"))
+		case m[10] >= 0:
+			out.WriteString("[::b]" + group(5) + "[::-]")
+		case m[12] >= 0:
+			out.WriteString("[::b]" + group(6) + "[::-]")
+		case m[14] >= 0:
+			out.WriteString("[::i]" + group(7) + "[::-]")
+		case m[16] >= 0:
+			out.WriteString("[::i]" + group(8) + "[::-]")
+		}
+	}
+	out.WriteString(tview.Escape(line[last:]))
+	return out.String()
+}
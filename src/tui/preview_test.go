@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func TestUpperText(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"Overview", "OVERVIEW"},
+		{"The `main` loop", "THE `main` LOOP"},
+		{"See [[Api Notes]] first", "SEE [[Api Notes]] FIRST"},
+		{"Read [the guide](docs/Guide.md)", "READ [THE GUIDE](docs/Guide.md)"},
+		{"A **bold** and *quiet* title", "A **BOLD** AND *QUIET* TITLE"},
+		{"`a` and [[b]]", "`a` AND [[b]]"},
+	}
+	for _, tt := range tests {
+		if got := upperText(tt.text); got != tt.want {
+			t.Errorf("upperText(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// maxProblemSeqs bounds how many occurrences of one problem are remembered
+// for jumping to the logs view
+const maxProblemSeqs = 50
+
+// problem is a warning or error, counted over all its occurrences in the
+// same phase and file
+type problem struct {
+	Level     string
+	Content   string
+	Phase     int
+	PhaseName string
+	File      string
+	Count     int
+	First     string // timestamp of the first occurrence
+	Last      string
+	Seqs      []int // event sequences of the latest occurrences, oldest first
+}
+
+// problemSet collects the warnings and errors of the run
+type problemSet struct {
+	byKey    map[string]*problem
+	order    []*problem // first occurrence order
+	warnings int        // occurrences, not distinct problems
+	errors   int
+}
+
+func newProblemSet() *problemSet {
+	return &problemSet{byKey: map[string]*problem{}}
+}
+
+// setupProblems creates the problems panel below the logs view
+func (t *TUI) setupProblems() {
+	root := tview.NewTreeNode("problems")
+	t.problemsTree = tview.NewTreeView().
+		SetRoot(root).
+		SetTopLevel(1)
+	t.problemsTree.SetBorder(true).
+		SetTitleAlign(tview.AlignLeft)
+	t.updateProblemsTitle()
+}
+
+// noteProblem adds a recorded log event to the problems panel if it is a
+// warning or an error
+func (t *TUI) noteProblem(e Event) {
+	if e.Type != "log" || (e.Level != "warning" && e.Level != "error") {
+		return
+	}
+	set := t.problems
+	if e.Level == "error" {
+		set.errors++
+	} else {
+		set.warnings++
+	}
+
+	key := strings.Join([]string{e.Level, fmt.Sprint(e.Phase), t.files.Current, e.Content}, "\x00")
+	p, ok := set.byKey[key]
+	if !ok {
+		p = &problem{
+			Level:     e.Level,
+			Content:   e.Content,
+			Phase:     e.Phase,
+			PhaseName: e.PhaseName,
+			File:      t.files.Current,
+			First:     e.Timestamp,
+		}
+		set.byKey[key] = p
+		set.order = append(set.order, p)
+	}
+	p.Count++
+	p.Last = e.Timestamp
+	p.Seqs = append(p.Seqs, e.Seq)
+	if len(p.Seqs) > maxProblemSeqs {
+		p.Seqs = p.Seqs[1:]
+	}
+
+	if !ok && len(set.order) == 1 && !t.problemsHidden {
+		// The panel appears with the first problem
+		t.showProblemsPanel(true)
+	}
+	t.updateProblems()
+	t.updateInfoBox()
+}
+
+// updateProblems rebuilds the tree: phases, then files, then problems,
+// keeping the selection and collapsed groups
+func (t *TUI) updateProblems() {
+	var selected interface{}
+	if node := t.problemsTree.GetCurrentNode(); node != nil {
+		selected = node.GetReference()
+	}
+	collapsed := map[string]bool{}
+	t.problemsTree.GetRoot().Walk(func(node, parent *tview.TreeNode) bool {
+		if key, ok := node.GetReference().(string); ok && !node.IsExpanded() {
+			collapsed[key] = true
+		}
+		return true
+	})
+
+	th := t.theme
+	root := tview.NewTreeNode("problems")
+	groups := map[string]*tview.TreeNode{}
+	group := func(parent *tview.TreeNode, key, text string) *tview.TreeNode {
+		if node, ok := groups[key]; ok {
+			return node
+		}
+		node := tview.NewTreeNode(text).
+			SetReference(key).
+			SetColor(th.Color("label")).
+			SetExpanded(!collapsed[key])
+		node.SetSelectedFunc(func() { node.SetExpanded(!node.IsExpanded()) })
+		parent.AddChild(node)
+		groups[key] = node
+		return node
+	}
+
+	var current, first *tview.TreeNode
+	for _, p := range t.problems.order {
+		phaseName := "Before any phase"
+		if p.Phase > 0 || p.PhaseName != "" {
+			phaseName = fmt.Sprintf("Phase %d: %s", p.Phase, p.PhaseName)
+		}
+		phaseKey := fmt.Sprintf("phase %d %s", p.Phase, p.PhaseName)
+		parent := group(root, phaseKey, phaseName)
+		if p.File != "" {
+			parent = group(parent, phaseKey+"\x00"+p.File, p.File)
+		}
+
+		icon, role := "\uf071", "warning"
+		if p.Level == "error" {
+			icon, role = "\uf00d", "error"
+		}
+		text := icon + " " + p.Content
+		if p.Count > 1 {
+			text = fmt.Sprintf("%s ×%d  %s (last %s)", icon, p.Count, p.Content, p.Last)
+		}
+		problem := p
+		node := tview.NewTreeNode(text).
+			SetReference(p).
+			SetColor(th.Color(role))
+		node.SetSelectedFunc(func() { t.jumpToProblem(problem) })
+		parent.AddChild(node)
+		if first == nil {
+			first = node
+		}
+		if p == selected {
+			current = node
+		}
+	}
+	// Group headers show how many problems they hold
+	for _, node := range groups {
+		count := 0
+		node.Walk(func(n, _ *tview.TreeNode) bool {
+			if p, ok := n.GetReference().(*problem); ok {
+				count += p.Count
+			}
+			return true
+		})
+		node.SetText(fmt.Sprintf("%s (%d)", node.GetText(), count))
+	}
+	if current == nil {
+		if key, ok := selected.(string); ok {
+			current = groups[key]
+		}
+	}
+	if current == nil {
+		current = first
+	}
+
+	t.problemsTree.SetRoot(root)
+	if current != nil {
+		t.problemsTree.SetCurrentNode(current)
+	}
+	t.updateProblemsTitle()
+}
+
+// updateProblemsTitle shows the totals in the panel border
+func (t *TUI) updateProblemsTitle() {
+	set := t.problems
+	t.problemsTree.SetTitle(fmt.Sprintf(t.theme.C(" problems [warning]\uf071 %d[text] [error]\uf00d %d[text] "),
+		set.warnings, set.errors))
+}
+
+// jumpToProblem shows the newest occurrence of a problem that is still in
+// the logs view
+func (t *TUI) jumpToProblem(p *problem) {
+	if t.viewMode != "normal" {
+		t.switchView("normal")
+	}
+	for i := len(p.Seqs) - 1; i >= 0; i-- {
+		region := eventRegion(p.Seqs[i])
+		if t.mainView.GetRegionText(region) == "" {
+			continue
+		}
+		t.mainView.Highlight(region).ScrollToHighlight()
+		t.focusedWidget = "main"
+		t.app.SetFocus(t.mainView)
+		t.updateShortcuts()
+		return
+	}
+	t.showToast("That entry is no longer in the logs view (cleared, filtered or trimmed)")
+}
+
+// toggleProblems shows or hides the problems panel
+func (t *TUI) toggleProblems() {
+	t.problemsHidden = !t.problemsHidden
+	t.showProblemsPanel(!t.problemsHidden)
+	if t.viewMode != "normal" {
+		t.switchView("normal")
+	}
+}
+
+// showProblemsPanel sizes the panel in the normal page
+func (t *TUI) showProblemsPanel(show bool) {
+	proportion := 0
+	if show && t.layout != "minimal" {
+		proportion = 1
+	}
+	t.normalPage.ResizeItem(t.problemsTree, 0, proportion)
+	if !show && t.focusedWidget == "problems" {
+		t.focusedWidget = "main"
+		t.app.SetFocus(t.getCurrentView())
+	}
+}
+
+// problemsVisible reports whether the panel takes up space
+func (t *TUI) problemsVisible() bool {
+	return !t.problemsHidden && len(t.problems.order) > 0 && t.layout != "minimal"
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Linux reports CPU times in clock ticks; USER_HZ is 100 on every
+// mainstream architecture
+const clockTicks = 100
+
+// pageSize converts RSS pages from /proc/PID/stat to bytes
+var pageSize = uint64(os.Getpagesize())
+
+// procInfo is one process as read from /proc/PID/stat
+type procInfo struct {
+	PID        int
+	PPID       int
+	Comm       string
+	State      string // R running, S sleeping, D disk wait, Z zombie, T stopped
+	Threads    int
+	CPUTicks   uint64 // utime + stime
+	StartTicks uint64 // since boot
+	RSS        uint64 // bytes
+}
+
+// AgentStats is a sample of the agent process and all of its descendants
+type AgentStats struct {
+	PID        int
+	Alive      bool
+	Processes  int
+	RSS        uint64  // bytes, summed over the tree
+	CPUPercent float64 // of one core, summed over the tree
+	ReadBytes  uint64  // storage IO, summed over the tree
+	WriteBytes uint64
+	Threads    int
+	FDs        int
+	ReportedMB int    // heap size the agent sent in a memory message
+	Err        string // why /proc could not be read, if it couldn't
+}
+
+// readProcStat reads /proc/PID/stat
+func readProcStat(pid int) (procInfo, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return procInfo{}, err
+	}
+	return parseProcStat(pid, string(data))
+}
+
+// parseProcStat parses the contents of /proc/PID/stat. The command name is
+// in parentheses and may itself contain spaces and parentheses, so the line
+// is split around the last ")".
+func parseProcStat(pid int, text string) (procInfo, error) {
+	open, end := strings.IndexByte(text, '('), strings.LastIndexByte(text, ')')
+	if open < 0 || end < open {
+		return procInfo{}, fmt.Errorf("malformed /proc/%d/stat", pid)
+	}
+	fields := strings.Fields(text[end+1:])
+	if len(fields) < 22 {
+		return procInfo{}, fmt.Errorf("malformed /proc/%d/stat", pid)
+	}
+	// fields[0] is field 3 of proc(5)
+	field := func(n int) uint64 {
+		v, _ := strconv.ParseUint(fields[n-3], 10, 64)
+		return v
+	}
+	return procInfo{
+		PID:        pid,
+		PPID:       int(field(4)),
+		Comm:       text[open+1 : end],
+		State:      fields[0],
+		Threads:    int(field(20)),
+		CPUTicks:   field(14) + field(15),
+		StartTicks: field(22),
+		RSS:        field(24) * pageSize,
+	}, nil
+}
+
+// readProcIO returns the bytes a process read from and wrote to storage.
+// /proc/PID/io is only readable for our own processes.
+func readProcIO(pid int) (read, write uint64) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/io", pid))
+	if err != nil {
+		return 0, 0
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			continue
+		}
+		n, _ := strconv.ParseUint(value, 10, 64)
+		switch key {
+		case "read_bytes":
+			read = n
+		case "write_bytes":
+			write = n
+		}
+	}
+	return read, write
+}
+
+// countFDs returns the number of open file descriptors of a process
+func countFDs(pid int) int {
+	entries, err := os.ReadDir(fmt.Sprintf("/proc/%d/fd", pid))
+	if err != nil {
+		return 0
+	}
+	return len(entries)
+}
+
+// readProcs reads every process in /proc
+func readProcs() (map[int]procInfo, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+	procs := map[int]procInfo{}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		if info, err := readProcStat(pid); err == nil {
+			procs[pid] = info
+		}
+	}
+	return procs, nil
+}
+
+// readCmdline returns the command line of a process, or its name in
+// brackets for kernel threads and zombies
+func readCmdline(info procInfo) string {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", info.PID))
+	cmdline := strings.TrimSpace(strings.ReplaceAll(string(data), "\x00", " "))
+	if err != nil || cmdline == "" {
+		return "[" + info.Comm + "]"
+	}
+	return cmdline
+}
+
+// bootTime returns when the system booted, from the btime line of /proc/stat
+func bootTime() time.Time {
+	data, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return time.Time{}
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if value, ok := strings.CutPrefix(line, "btime "); ok {
+			secs, _ := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			return time.Unix(secs, 0)
+		}
+	}
+	return time.Time{}
+}
+
+// treeEntry is a process in a tree walk with its depth below the root
+type treeEntry struct {
+	PID   int
+	Depth int
+}
+
+// procTree returns root and every process below it depth first, children
+// ordered by PID
+func procTree(procs map[int]procInfo, root int) []treeEntry {
+	children := map[int][]int{}
+	for pid, info := range procs {
+		children[info.PPID] = append(children[info.PPID], pid)
+	}
+	var tree []treeEntry
+	var walk func(pid, depth int)
+	walk = func(pid, depth int) {
+		tree = append(tree, treeEntry{pid, depth})
+		kids := children[pid]
+		sort.Ints(kids)
+		for _, kid := range kids {
+			walk(kid, depth+1)
+		}
+	}
+	walk(root, 0)
+	return tree
+}
+
+// procRow is one line of the process tree panel
+type procRow struct {
+	procInfo
+	Depth      int
+	Cmdline    string
+	CPUPercent float64
+	Elapsed    time.Duration // since the process started
+	IdleFor    time.Duration // since it last used any CPU
+}
+
+// procSampler turns CPU tick counters into percentages between samples
+type procSampler struct {
+	lastTicks  map[int]uint64
+	lastActive map[int]time.Time
+	lastTime   time.Time
+	boot       time.Time
+}
+
+func newProcSampler() *procSampler {
+	return &procSampler{
+		lastTicks:  map[int]uint64{},
+		lastActive: map[int]time.Time{},
+		boot:       bootTime(),
+	}
+}
+
+// sample reads the agent and its descendants. CPU is the share of one core
+// used since the previous sample; processes that are new since then count
+// from the next sample on.
+func (s *procSampler) sample(pid int) (AgentStats, []procRow) {
+	stats := AgentStats{PID: pid}
+	if pid <= 0 {
+		return stats, nil
+	}
+	procs, err := readProcs()
+	if err != nil {
+		stats.Err = "/proc not available"
+		return stats, nil
+	}
+	if _, ok := procs[pid]; !ok {
+		s.lastTicks = map[int]uint64{}
+		s.lastActive = map[int]time.Time{}
+		return stats, nil
+	}
+	stats.Alive = true
+
+	now := time.Now()
+	elapsed := now.Sub(s.lastTime).Seconds()
+	ticks := map[int]uint64{}
+	active := map[int]time.Time{}
+	var rows []procRow
+	var busy uint64
+	for _, entry := range procTree(procs, pid) {
+		p := entry.PID
+		info := procs[p]
+		stats.Processes++
+		stats.RSS += info.RSS
+		stats.Threads += info.Threads
+		stats.FDs += countFDs(p)
+		read, write := readProcIO(p)
+		stats.ReadBytes += read
+		stats.WriteBytes += write
+
+		row := procRow{procInfo: info, Depth: entry.Depth, Cmdline: readCmdline(info)}
+		if !s.boot.IsZero() {
+			started := s.boot.Add(time.Duration(info.StartTicks) * time.Second / clockTicks)
+			row.Elapsed = now.Sub(started)
+		}
+
+		ticks[p] = info.CPUTicks
+		active[p] = now
+		if last, ok := s.lastTicks[p]; ok && info.CPUTicks >= last {
+			used := info.CPUTicks - last
+			busy += used
+			if elapsed > 0 {
+				row.CPUPercent = float64(used) / clockTicks / elapsed * 100
+			}
+			if used == 0 {
+				active[p] = s.lastActive[p]
+			}
+		}
+		row.IdleFor = now.Sub(active[p])
+		rows = append(rows, row)
+	}
+	if !s.lastTime.IsZero() && elapsed > 0 {
+		stats.CPUPercent = float64(busy) / clockTicks / elapsed * 100
+	}
+	s.lastTicks = ticks
+	s.lastActive = active
+	s.lastTime = now
+	return stats, rows
+}
+
+// setAgentPID sets the process whose stats are sampled
+func (t *TUI) setAgentPID(pid int) {
+	t.agentPID.Store(int64(pid))
+}
+
+// updateProcessStats samples the TUI itself and the agent once a second
+func (t *TUI) updateProcessStats() {
+	sampler := newProcSampler()
+	ticker := time.NewTicker(1 * time.Second)
+	for range ticker.C {
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		own := ProcessStats{
+			MemoryMB:   int(m.Alloc / 1024 / 1024),
+			Goroutines: runtime.NumGoroutine(),
+		}
+		agent, rows := sampler.sample(int(t.agentPID.Load()))
+
+		t.app.QueueUpdateDraw(func() {
+			t.processStats = own
+			agent.ReportedMB = t.agentStats.ReportedMB
+			t.agentStats = agent
+			t.procRows = rows
+			t.sampleHistory()
+			t.updateStatsBox()
+			if t.viewMode == "debug" {
+				t.updateInfoBox()
+				t.updateProcTable()
+				t.updateTrends()
+			}
+		})
+	}
+}
+
+// formatBytes renders a byte count as B, KB, MB or GB
+func formatBytes(n uint64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1fGB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%dMB", n>>20)
+	case n >= 1<<10:
+		return fmt.Sprintf("%dKB", n>>10)
+	}
+	return fmt.Sprintf("%dB", n)
+}
+
+// agentSummary is the one-line agent status for the stats box
+func (t *TUI) agentSummary() string {
+	a := t.agentStats
+	switch {
+	case a.PID == 0:
+		if a.ReportedMB > 0 {
+			return fmt.Sprintf("%dMB heap", a.ReportedMB)
+		}
+		return "no PID"
+	case a.Err != "":
+		return a.Err
+	case !a.Alive:
+		return fmt.Sprintf("%d exited", a.PID)
+	}
+	return fmt.Sprintf("%s %.0f%%", formatBytes(a.RSS), a.CPUPercent)
+}
+
+// agentDetails is the agent section of the info box in the debug view
+func (t *TUI) agentDetails() string {
+	a := t.agentStats
+	th := t.theme
+	if a.PID == 0 || a.Err != "" || !a.Alive {
+		return fmt.Sprintf(th.C("[label2]Agent:[text]  %s"), t.agentSummary())
+	}
+	details := fmt.Sprintf(th.C("[label2]Agent:[text]  pid %d, %d process(es)\n"+
+		"[label2]RSS:[text]    %-10s [label2]CPU:[text] %-7s [label2]Threads:[text] %-5d [label2]FDs:[text] %d\n"+
+		"[label2]IO:[text]     read %s, written %s"),
+		a.PID, a.Processes,
+		formatBytes(a.RSS), fmt.Sprintf("%.1f%%", a.CPUPercent), a.Threads, a.FDs,
+		formatBytes(a.ReadBytes), formatBytes(a.WriteBytes))
+	if a.ReportedMB > 0 {
+		details += fmt.Sprintf(th.C(", heap %dMB"), a.ReportedMB)
+	}
+	return details
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseProcStat(t *testing.T) {
+	// Fields 3 to 24 of proc(5): state, ppid, ..., utime 30, stime 12, ...,
+	// num_threads 3, ..., starttime 5000, vsize, rss 250
+	rest := "S 7 42 42 0 -1 4194560 100 0 0 0 30 12 0 0 20 0 3 0 5000 1000000 250"
+	tests := []struct {
+		name, stat string
+		comm       string
+	}{
+		{"plain", "42 (node) " + rest, "node"},
+		{"spaces", "42 (npm run start) " + rest, "npm run start"},
+		{"parentheses", "42 (my (odd) proc) " + rest, "my (odd) proc"},
+		{"closing parenthesis", "42 (a) b) " + rest, "a) b"},
+		{"trailing fields", "42 (node) " + rest + " 18446744073709551615 1 1\n", "node"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := parseProcStat(42, tt.stat)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := procInfo{PID: 42, PPID: 7, Comm: tt.comm, State: "S", Threads: 3,
+				CPUTicks: 42, StartTicks: 5000, RSS: 250 * pageSize}
+			if info != want {
+				t.Errorf("parseProcStat = %+v, want %+v", info, want)
+			}
+		})
+	}
+
+	malformed := []string{
+		"",
+		"42 node " + rest,
+		"42 (node " + rest,
+		"42 node) " + rest,
+		"42 )node( " + rest,
+		"42 (node) " + rest[:strings.LastIndexByte(rest, ' ')],
+	}
+	for _, stat := range malformed {
+		if _, err := parseProcStat(42, stat); err == nil {
+			t.Errorf("parseProcStat(%q) succeeded", stat)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// setupProcTable creates the process tree panel of the debug view
+func (t *TUI) setupProcTable() {
+	t.procTable = tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	t.procTable.SetBorder(true).
+		SetTitle(" processes ").
+		SetTitleAlign(tview.AlignLeft)
+	t.updateProcTable()
+}
+
+// updateProcTable redraws the process tree from the last sample, keeping
+// the selected process selected
+func (t *TUI) updateProcTable() {
+	selected := t.selectedProc()
+
+	t.procTable.Clear()
+	headers := []string{"PID", "STATE", "CPU", "ELAPSED", "IDLE", "COMMAND"}
+	for col, header := range headers {
+		t.procTable.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(t.theme.Color("accent")).
+			SetSelectable(false))
+	}
+
+	if len(t.procRows) == 0 {
+		message := "No agent process to show"
+		if t.agentStats.Err != "" {
+			message = t.agentStats.Err
+		}
+		t.procTable.SetCell(1, 0, tview.NewTableCell(message).
+			SetTextColor(t.theme.Color("muted")).
+			SetSelectable(false))
+		return
+	}
+
+	idleLimit := t.config.Monitor.IdleDuration()
+	for i, row := range t.procRows {
+		role := "text"
+		switch {
+		case row.State == "Z" || row.State == "D":
+			role = "error"
+		case row.IdleFor >= idleLimit:
+			role = "warning"
+		}
+		color := t.theme.Color(role)
+
+		prefix := ""
+		if row.Depth > 0 {
+			prefix = strings.Repeat("  ", row.Depth-1) + "└─ "
+		}
+		idle := ""
+		if row.IdleFor >= time.Second {
+			idle = formatElapsed(row.IdleFor)
+		}
+		cells := []string{
+			fmt.Sprintf("%d", row.PID),
+			row.State,
+			fmt.Sprintf("%.1f%%", row.CPUPercent),
+			formatElapsed(row.Elapsed),
+			idle,
+			prefix + row.Cmdline,
+		}
+		for col, text := range cells {
+			cell := tview.NewTableCell(tview.Escape(text)).SetTextColor(color)
+			if col == len(cells)-1 {
+				cell.SetExpansion(1)
+			}
+			t.procTable.SetCell(i+1, col, cell)
+		}
+	}
+
+	// Keep the selection on the same process
+	row := 1
+	for i, r := range t.procRows {
+		if r.PID == selected {
+			row = i + 1
+		}
+	}
+	t.procTable.Select(row, 0)
+}
+
+// selectedProc returns the PID of the selected row, 0 if none
+func (t *TUI) selectedProc() int {
+	row, _ := t.procTable.GetSelection()
+	if row < 1 || row > len(t.procRows) {
+		return 0
+	}
+	return t.procRows[row-1].PID
+}
+
+// canSignalSelected reports whether the selected row is a child of the
+// agent that may be signalled
+func (t *TUI) canSignalSelected() bool {
+	pid := t.selectedProc()
+	return t.viewMode == "debug" && pid > 0 && pid != int(t.agentPID.Load())
+}
+
+// confirmSignal asks which signal to send to the selected child
+func (t *TUI) confirmSignal() {
+	pid := t.selectedProc()
+	var target procRow
+	for _, row := range t.procRows {
+		if row.PID == pid {
+			target = row
+		}
+	}
+
+	closeModal := func() {
+		t.modalOpen = false
+		t.app.SetRoot(t.rootPages, true)
+		t.app.SetFocus(t.getCurrentView())
+	}
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Send a signal to PID %d?\n\n%s", pid, truncateMiddle(target.Cmdline, 60))).
+		AddButtons([]string{"SIGTERM", "SIGKILL", "Cancel"}).
+		SetDoneFunc(func(_ int, label string) {
+			closeModal()
+			switch label {
+			case "SIGTERM":
+				t.sendSignal(target, syscall.SIGTERM)
+			case "SIGKILL":
+				t.sendSignal(target, syscall.SIGKILL)
+			}
+		})
+	modal.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc {
+			closeModal()
+			return nil
+		}
+		return event
+	})
+
+	t.modalOpen = true
+	t.app.SetRoot(modal, true)
+	t.app.SetFocus(modal)
+}
+
+// sendSignal signals a child of the agent after checking that the PID
+// still belongs to the same process and has not been reused
+func (t *TUI) sendSignal(target procRow, sig syscall.Signal) {
+	timestamp := time.Now().Format("15:04:05")
+	current, err := readProcStat(target.PID)
+	if err != nil || current.StartTicks != target.StartTicks {
+		t.addLog("warning", fmt.Sprintf("PID %d has already exited", target.PID), timestamp)
+		return
+	}
+	if err := syscall.Kill(target.PID, sig); err != nil {
+		t.addLog("error", fmt.Sprintf("Failed to send %s to PID %d: %v", sigName(sig), target.PID, err), timestamp)
+		return
+	}
+	t.addLog("warning", fmt.Sprintf("Sent %s to PID %d (%s)", sigName(sig), target.PID, target.Comm), timestamp)
+}
+
+func sigName(sig syscall.Signal) string {
+	if sig == syscall.SIGKILL {
+		return "SIGKILL"
+	}
+	return "SIGTERM"
+}
+
+// formatElapsed renders a duration as 45s, 12m03s or 2h05m
+func formatElapsed(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	}
+	return fmt.Sprintf("%ds", int(d.Seconds()))
+}
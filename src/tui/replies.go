@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// repliesEnv tells the agent where the TUI's answers arrive: "stdin", or the
+// path given with -replies
+const repliesEnv = "DOCUMENTOR_TUI_REPLIES"
+
+// startReplies starts writing answers to the agent, one JSON object per
+// line. open is called on the first answer: opening a FIFO blocks until
+// the agent opens it too, so it happens off the UI goroutine.
+func (t *TUI) startReplies(open func() (io.Writer, error)) {
+	t.replies = make(chan []byte, 64)
+	go func() {
+		var w io.Writer
+		for line := range t.replies {
+			var err error
+			if w == nil {
+				w, err = open()
+			}
+			if err == nil {
+				_, err = w.Write(line)
+			}
+			if err != nil {
+				w = nil
+				timestamp := time.Now().Format("15:04:05")
+				t.app.QueueUpdateDraw(func() {
+					t.addLog("error", fmt.Sprintf("Cannot answer the agent: %v", err), timestamp)
+				})
+			}
+		}
+	}()
+}
+
+// checkRepliesFIFO makes sure the -replies path is a FIFO: the agent reads
+// a regular file once up to its end and never sees the answers added later
+func checkRepliesFIFO(path string) error {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%s does not exist, create it with mkfifo", path)
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeNamedPipe == 0 {
+		return fmt.Errorf("%s is not a FIFO, create one with mkfifo", path)
+	}
+	return nil
+}
+
+// openRepliesFile opens the -replies FIFO for writing
+func openRepliesFile(path string) func() (io.Writer, error) {
+	return func() (io.Writer, error) {
+		return os.OpenFile(path, os.O_WRONLY, 0)
+	}
+}
+
+// reply sends an answer to the agent. It reports false if there is no
+// return channel or the answer could not be queued.
+func (t *TUI) reply(v interface{}) bool {
+	if t.replies == nil {
+		return false
+	}
+	line, err := json.Marshal(v)
+	if err != nil {
+		t.addLog("error", fmt.Sprintf("Cannot encode answer: %v", err), time.Now().Format("15:04:05"))
+		return false
+	}
+	select {
+	case t.replies <- append(line, '\n'):
+		return true
+	default:
+		t.addLog("error", "Cannot answer the agent: too many answers waiting", time.Now().Format("15:04:05"))
+		return false
+	}
+}
+
+// PasswordResponse answers a password_request
+type PasswordResponse struct {
+	Type      string `json:"type"` // "password_response"
+	RequestID string `json:"requestId"`
+	Password  string `json:"password"`
+	Cancelled bool   `json:"cancelled"`
+}
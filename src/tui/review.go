@@ -0,0 +1,335 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Review marks of a written file
+const (
+	reviewPending  = "pending"
+	reviewAccepted = "accepted"
+	reviewRejected = "rejected"
+	reviewReverted = "reverted"
+)
+
+// reviewStyles are the glyph and theme role of each mark
+var reviewStyles = map[string]fileStatusStyle{
+	reviewPending:  {reviewPending, "", "muted"},
+	reviewAccepted: {reviewAccepted, "", "success"},
+	reviewRejected: {reviewRejected, "", "error"},
+	reviewReverted: {reviewReverted, "", "warning"},
+}
+
+// reviewFiles lists every file the run wrote, from Write and Edit calls and
+// the summary's manifest, in the order they were first written. Only
+// absolute paths count: resolved against the project, a bare name may be
+// another file than the one written.
+func (t *TUI) reviewFiles() []string {
+	seen := map[string]bool{}
+	var files []string
+	add := func(path string) {
+		if path = t.projectFile(path); path != "" && !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+	// noteChange keeps only the calls that named an absolute path
+	for _, path := range t.changes.written {
+		add(path)
+	}
+	if t.summary != nil {
+		for _, path := range t.summary.Written {
+			if filepath.IsAbs(path) {
+				add(path)
+			}
+		}
+	}
+	return files
+}
+
+// original returns a file as it was before the run and where that came
+// from: the TUI's snapshot when it saw the file before it was written, or
+// else the committed version of a file git tracks. Anything else is an
+// error, so a file is never deleted or checked out on a guess.
+func (t *TUI) original(path string) (snapshot, string, error) {
+	if base, ok := t.changes.base[path]; ok && !base.truncated && !base.afterWrite {
+		return base, "snapshot", nil
+	}
+	abs := t.resolvePath(path)
+	dir, name := filepath.Dir(abs), "./"+filepath.Base(abs)
+	if err := exec.Command("git", "-C", dir, "ls-files", "--error-unmatch", "--", name).Run(); err != nil {
+		return snapshot{}, "", fmt.Errorf("no snapshot of %s from before the run and git does not track it", path)
+	}
+	data, err := exec.Command("git", "-C", dir, "show", "HEAD:"+name).Output()
+	if err != nil {
+		return snapshot{}, "", fmt.Errorf("no snapshot of %s from before the run and it is not in git HEAD", path)
+	}
+	return snapshot{content: string(data), exists: true}, "git HEAD", nil
+}
+
+// revertFile restores a file as it was before the run, deleting it if the
+// run created it
+func (t *TUI) revertFile(path string) (string, error) {
+	orig, source, err := t.original(path)
+	if err != nil {
+		return "", err
+	}
+	abs := t.resolvePath(path)
+	switch {
+	case !orig.exists:
+		if err := os.Remove(abs); err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+		return "deleted, the run created it", nil
+	case source == "git HEAD":
+		out, err := exec.Command("git", "-C", filepath.Dir(abs), "checkout", "HEAD", "--", "./"+filepath.Base(abs)).CombinedOutput()
+		if err != nil {
+			return "", fmt.Errorf("git checkout: %s", strings.TrimSpace(string(out)))
+		}
+		return "restored with git checkout", nil
+	}
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(abs); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.WriteFile(abs, []byte(orig.content), mode); err != nil {
+		return "", err
+	}
+	return "restored from the snapshot", nil
+}
+
+// reviewer is the open review page
+type reviewer struct {
+	*diffPane
+	files   []string
+	list    *tview.List
+	footer  *tview.TextView
+	page    *tview.Flex
+	current int
+	preview bool // the file is shown rendered instead of as a diff
+	confirm bool // X was pressed once, the next X reverts
+}
+
+// showReview opens the review page on the first file not reviewed yet
+func (t *TUI) showReview() {
+	files := t.reviewFiles()
+	if len(files) == 0 {
+		t.showToast("The run has not written any files yet")
+		return
+	}
+	th := t.theme
+	r := &reviewer{
+		diffPane: newDiffPane(t.config.Diff == "side-by-side"),
+		files:    files,
+		list:     tview.NewList().ShowSecondaryText(false).SetHighlightFullLine(true),
+		footer:   tview.NewTextView().SetDynamicColors(true),
+	}
+	r.render = func() string { return t.renderReview(r) }
+	r.list.SetBorder(true).
+		SetTitleAlign(tview.AlignLeft)
+	r.list.SetMainTextColor(th.Color("text")).
+		SetSelectedBackgroundColor(th.Color("accent"))
+	for range files {
+		r.list.AddItem("", "", 0, nil)
+	}
+	r.list.SetChangedFunc(func(index int, _, _ string, _ rune) {
+		r.current = index
+		t.loadReview(r)
+	})
+	r.footer.SetBorder(true).
+		SetBorderPadding(0, 0, 1, 1)
+
+	listWidth := filesWidth
+	if t.layout != "full" {
+		listWidth = filesWidthCompact
+	}
+	r.page = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tview.NewFlex().
+			AddItem(r.list, listWidth, 0, true).
+			AddItem(r.view, 0, 1, false), 0, 1, true).
+		AddItem(r.footer, 3, 0, false)
+
+	r.page.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		confirm := r.confirm
+		r.confirm = false
+		switch {
+		case event.Key() == tcell.KeyEsc:
+			t.modalOpen = false
+			t.app.SetRoot(t.rootPages, true)
+			t.app.SetFocus(t.getCurrentView())
+			return nil
+		case event.Key() == tcell.KeyTab || event.Key() == tcell.KeyBacktab:
+			if r.list.HasFocus() {
+				t.app.SetFocus(r.view)
+			} else {
+				t.app.SetFocus(r.list)
+			}
+		case event.Rune() == 'q' || event.Rune() == 'Q':
+			t.app.Stop()
+		case event.Rune() == 'a' || event.Rune() == 'A':
+			t.markReview(r, reviewAccepted)
+		case event.Rune() == 'r':
+			t.markReview(r, reviewRejected)
+		case event.Rune() == 'u' || event.Rune() == 'U':
+			t.markReview(r, reviewPending)
+		case event.Rune() == 'p' || event.Rune() == 'P':
+			r.preview = !r.preview
+			t.loadReview(r)
+		case event.Rune() == 's' || event.Rune() == 'S':
+			r.sideBySide = !r.sideBySide
+			t.loadReview(r)
+		case event.Rune() == 'X':
+			if confirm {
+				t.revertRejected(r)
+			} else if n := t.countMarks(r.files, reviewRejected); n > 0 {
+				r.confirm = true
+				r.footer.SetText(fmt.Sprintf(th.C("[warning]Revert %d rejected file(s)? Press X again to confirm, any other key cancels[text]"), n))
+				return nil
+			}
+		default:
+			t.updateReviewFooter(r)
+			return event
+		}
+		t.updateReviewFooter(r)
+		return nil
+	})
+
+	r.current = 0
+	for i, path := range files {
+		if t.reviewMark(path) == reviewPending {
+			r.current = i
+			break
+		}
+	}
+	t.updateReviewList(r)
+	r.list.SetCurrentItem(r.current)
+	t.loadReview(r)
+	t.updateReviewFooter(r)
+	t.modalOpen = true
+	t.app.SetRoot(r.page, true)
+	t.app.SetFocus(r.list)
+}
+
+// reviewMark returns the mark of a file, pending if it has none
+func (t *TUI) reviewMark(path string) string {
+	if mark, ok := t.reviewMarks[path]; ok {
+		return mark
+	}
+	return reviewPending
+}
+
+// countMarks counts the files with a mark
+func (t *TUI) countMarks(files []string, mark string) int {
+	n := 0
+	for _, path := range files {
+		if t.reviewMark(path) == mark {
+			n++
+		}
+	}
+	return n
+}
+
+// markReview marks the current file and steps to the next one
+func (t *TUI) markReview(r *reviewer, mark string) {
+	path := r.files[r.current]
+	if t.reviewMark(path) == reviewReverted {
+		t.showToast("Already reverted")
+		return
+	}
+	t.reviewMarks[path] = mark
+	t.updateReviewList(r)
+	if mark != reviewPending && r.current+1 < len(r.files) {
+		r.list.SetCurrentItem(r.current + 1)
+	}
+}
+
+// revertRejected restores every rejected file and logs what happened
+func (t *TUI) revertRejected(r *reviewer) {
+	timestamp := time.Now().Format("15:04:05")
+	for _, path := range r.files {
+		if t.reviewMark(path) != reviewRejected {
+			continue
+		}
+		how, err := t.revertFile(path)
+		if err != nil {
+			t.addLog("error", fmt.Sprintf("Cannot revert %s: %v", path, err), timestamp)
+			continue
+		}
+		t.reviewMarks[path] = reviewReverted
+		t.addLog("warning", fmt.Sprintf("Reverted %s: %s", path, how), timestamp)
+	}
+	t.updateReviewList(r)
+	t.loadReview(r)
+}
+
+// updateReviewList redraws the marks and the counts in the list title
+func (t *TUI) updateReviewList(r *reviewer) {
+	th := t.theme
+	for i, path := range r.files {
+		style := reviewStyles[t.reviewMark(path)]
+		r.list.SetItemText(i, th.Tag(style.role)+style.icon+th.Tag("text")+" "+tview.Escape(path), "")
+	}
+	title := fmt.Sprintf(" review %d/%d ", len(r.files)-t.countMarks(r.files, reviewPending), len(r.files))
+	for _, mark := range []string{reviewAccepted, reviewRejected, reviewReverted} {
+		if n := t.countMarks(r.files, mark); n > 0 {
+			style := reviewStyles[mark]
+			title += fmt.Sprintf("%s%s %d%s ", th.Tag(style.role), style.icon, n, th.Tag("text"))
+		}
+	}
+	r.list.SetTitle(title)
+}
+
+// updateReviewFooter shows the keys of the review page
+func (t *TUI) updateReviewFooter(r *reviewer) {
+	mode := "preview"
+	if r.preview {
+		mode = "diff"
+	}
+	r.footer.SetText(t.theme.C(fmt.Sprintf("[label]A[text] accept  [label]r[text] reject  [label]U[text] undo  [label]X[text] revert rejected  [label]P[text] %s  [label]S[text] side by side  [label]Tab[text] focus  [label]Esc[text] back", mode)))
+}
+
+// loadReview shows the current file
+func (t *TUI) loadReview(r *reviewer) {
+	th := t.theme
+	what := "diff, " + r.mode()
+	if r.preview {
+		what = "preview"
+	}
+	r.view.SetWrap(r.preview).SetWordWrap(r.preview)
+	r.view.SetTitle(fmt.Sprintf(" %s %s%s%s ",
+		tview.Escape(r.files[r.current]), th.Tag("muted"), what, th.Tag("text")))
+	r.refresh()
+}
+
+// renderReview renders the current file as a diff against its content
+// before the run, or as a Markdown preview
+func (t *TUI) renderReview(r *reviewer) string {
+	th := t.theme
+	path := r.files[r.current]
+	now := t.readSnapshot(path)
+	if r.preview {
+		if !now.exists {
+			return th.C("[muted]The file does not exist.[text]")
+		}
+		front, body := splitFrontmatter(now.content)
+		text := t.renderMarkdown(body)
+		if front != "" {
+			text = t.renderFrontmatter(front) + "\n" + th.Tag("muted") + strings.Repeat("─", 40) + th.Tag("text") + "\n" + text
+		}
+		return text
+	}
+	orig, source, err := t.original(path)
+	if err != nil {
+		return fmt.Sprintf(th.C("[error]%s[text]\n\n"), tview.Escape(err.Error())) +
+			t.renderChange(snapshot{}, now, "Shown as a new file", r.diffPane)
+	}
+	return t.renderChange(orig, now, "Compared with the "+source, r.diffPane)
+}
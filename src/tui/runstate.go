@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// RunState is where the documentor run stands, as far as the TUI can tell
+type RunState string
+
+const (
+	StateWaiting       RunState = "waiting"        // nothing received yet
+	StateConnected     RunState = "connected"      // messages arrive, no work reported yet
+	StateWorking       RunState = "working"        // phases, files, tools or logs are coming in
+	StateStalled       RunState = "stalled"        // working, but silent for monitor.stallSeconds
+	StatePaused        RunState = "paused"         // the agent said so
+	StateAwaitingInput RunState = "awaiting-input" // a prompt is waiting for the user
+	StateCompleted     RunState = "completed"
+	StateFailed        RunState = "failed"
+	StateDisconnected  RunState = "disconnected" // input closed without a result
+)
+
+// runStateStyle is how a state is shown in the status box
+type runStateStyle struct {
+	label string
+	role  string
+	icon  string // empty: the spinner is running
+}
+
+var runStateStyles = map[RunState]runStateStyle{
+	StateWaiting:       {"Waiting", "muted", "…"},
+	StateConnected:     {"Connected", "info", "○"},
+	StateWorking:       {"Working", "success", ""},
+	StateStalled:       {"Stalled", "warning", "!"},
+	StatePaused:        {"Paused", "warning", "‖"},
+	StateAwaitingInput: {"Input needed", "accent", "?"},
+	StateCompleted:     {"Completed", "success", "✓"},
+	StateFailed:        {"Failed", "error", "✗"},
+	StateDisconnected:  {"Disconnected", "error", "⊘"},
+}
+
+// final reports whether the run is over
+func (s RunState) final() bool {
+	return s == StateCompleted || s == StateFailed
+}
+
+// setState moves to a new state and notes the transition in the debug view
+func (t *TUI) setState(state RunState, reason string) {
+	if state == t.state {
+		return
+	}
+	from := t.state
+	t.state = state
+	t.stateSince = time.Now()
+	if state.final() || state == StateDisconnected {
+		t.endTime = t.stateSince
+	} else {
+		t.endTime = time.Time{}
+	}
+
+	timestamp := time.Now().Format("15:04:05")
+	text := fmt.Sprintf("State: %s → %s", from, state)
+	if reason != "" {
+		text += " (" + reason + ")"
+	}
+	t.addDebug(text, timestamp)
+
+	switch state {
+	case StateCompleted:
+		t.addLog("success", "Run completed", timestamp)
+	case StateFailed:
+		t.addLog("error", "Run failed"+reasonSuffix(reason), timestamp)
+	case StateDisconnected:
+		t.addLog("warning", "Input closed before the run reported a result", timestamp)
+	case StateStalled:
+		t.addLog("warning", fmt.Sprintf("No message for %ds", t.config.Monitor.StallSeconds), timestamp)
+	}
+	t.updateStatsBox()
+	t.refreshSummary()
+	t.notifyState(state, reason)
+	if state.final() {
+		t.finishFiles(state == StateCompleted)
+		t.offerSummary()
+	}
+}
+
+func reasonSuffix(reason string) string {
+	if reason == "" {
+		return ""
+	}
+	return ": " + reason
+}
+
+// noteMessage drives the state from an incoming message
+func (t *TUI) noteMessage(msg Message) {
+	switch msg.Type {
+	case "state":
+		state := RunState(msg.State)
+		if _, ok := runStateStyles[state]; !ok || state == StateStalled || state == StateDisconnected {
+			t.addLog("warning", fmt.Sprintf("Unknown run state %q", msg.State), time.Now().Format("15:04:05"))
+			return
+		}
+		t.setState(state, msg.Content)
+		return
+	case "password_request":
+		t.setState(StateAwaitingInput, "password requested")
+		return
+	}
+
+	if t.state.final() {
+		// Late messages don't reopen a finished run
+		return
+	}
+	if msg.LockInfo.Status != "" {
+		switch msg.LockInfo.Status {
+		case "stale":
+			t.setState(StateFailed, fmt.Sprintf("stale lock of PID %d", msg.LockInfo.PID))
+			return
+		case "unlocked":
+			if t.state == StateWorking || t.state == StateStalled {
+				t.setState(StateCompleted, "lock released")
+				return
+			}
+		}
+	}
+
+	switch t.state {
+	case StatePaused, StateAwaitingInput:
+		// Only an explicit state or an answered prompt ends these
+		return
+	}
+	switch msg.Type {
+	case "phase", "file", "tool", "log":
+		t.setState(StateWorking, "")
+	default:
+		if t.state == StateWaiting || t.state == StateDisconnected {
+			t.setState(StateConnected, "")
+		} else if t.state == StateStalled {
+			t.setState(StateWorking, "")
+		}
+	}
+}
+
+// checkStall flags a working run that has gone quiet
+func (t *TUI) checkStall() {
+	if t.state == StateWorking && time.Since(t.lastUpdate) >= t.config.Monitor.StallDuration() {
+		t.setState(StateStalled, "")
+	}
+}
+
+// inputClosed is called once the message stream ends. A launched agent's
+// exit status settles the state instead.
+func (t *TUI) inputClosed() {
+	if t.agentCmd == nil && !t.state.final() {
+		t.setState(StateDisconnected, "")
+	}
+}
+
+// agentExited settles the state from the launched agent's exit status
+func (t *TUI) agentExited(err error) {
+	if t.state.final() {
+		return
+	}
+	if err != nil {
+		t.setState(StateFailed, err.Error())
+	} else {
+		t.setState(StateCompleted, "agent exited")
+	}
+}
+
+// stateDisplay returns the icon and colored label of the current state
+func (t *TUI) stateDisplay() (icon, label string) {
+	style := runStateStyles[t.state]
+	icon = style.icon
+	if icon == "" {
+		icon = t.spinnerChars[t.spinnerIndex]
+	}
+	return icon, t.theme.Tag(style.role) + style.label + t.theme.Tag("text")
+}
+
+// animating reports whether the spinner should move
+func (t *TUI) animating() bool {
+	return runStateStyles[t.state].icon == ""
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRunStateTransitions(t *testing.T) {
+	tool := Message{Type: "tool", Tool: "Read"}
+	memory := Message{Type: "memory"}
+	tests := []struct {
+		name  string
+		steps []Message
+		want  RunState
+	}{
+		{"nothing yet", nil, StateWaiting},
+		{"connected", []Message{memory}, StateConnected},
+		{"working", []Message{memory, tool}, StateWorking},
+		{"lock released", []Message{tool, {Type: "lockInfo", LockInfo: LockInfo{Status: "unlocked"}}}, StateCompleted},
+		{"released before any work", []Message{memory, {Type: "lockInfo", LockInfo: LockInfo{Status: "unlocked"}}}, StateConnected},
+		{"stale lock", []Message{tool, {Type: "lockInfo", LockInfo: LockInfo{Status: "stale", PID: 7}}}, StateFailed},
+		{"late message after the end", []Message{tool, {Type: "state", State: "completed"}, tool}, StateCompleted},
+		{"explicit state", []Message{tool, {Type: "state", State: "paused"}}, StatePaused},
+		{"paused ignores work", []Message{{Type: "state", State: "paused"}, tool}, StatePaused},
+		{"password prompt", []Message{tool, {Type: "password_request"}, tool}, StateAwaitingInput},
+		{"prompt answered", []Message{{Type: "password_request"}, {Type: "state", State: "working"}}, StateWorking},
+		{"stalled is not sent", []Message{tool, {Type: "state", State: "stalled"}}, StateWorking},
+		{"unknown state", []Message{memory, {Type: "state", State: "sleeping"}}, StateConnected},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tui := NewTUI(DefaultConfig())
+			for _, msg := range tt.steps {
+				tui.noteMessage(msg)
+			}
+			if tui.state != tt.want {
+				t.Errorf("state = %s, want %s", tui.state, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunStateStall(t *testing.T) {
+	tui := NewTUI(DefaultConfig())
+	stall := tui.config.Monitor.StallDuration()
+	tui.noteMessage(Message{Type: "tool"})
+
+	tui.lastUpdate = time.Now().Add(-stall / 2)
+	tui.checkStall()
+	if tui.state != StateWorking {
+		t.Fatalf("stalled after %v, want working", stall/2)
+	}
+
+	tui.lastUpdate = time.Now().Add(-stall)
+	tui.checkStall()
+	if tui.state != StateStalled {
+		t.Fatalf("state = %s after %v, want stalled", tui.state, stall)
+	}
+
+	// Any message resumes the run
+	tui.noteMessage(Message{Type: "memory"})
+	if tui.state != StateWorking {
+		t.Fatalf("state = %s after a message, want working", tui.state)
+	}
+
+	// Only a working run stalls
+	tui.noteMessage(Message{Type: "state", State: "paused"})
+	tui.lastUpdate = time.Now().Add(-2 * stall)
+	tui.checkStall()
+	if tui.state != StatePaused {
+		t.Errorf("state = %s, want paused", tui.state)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// ScrollbackConfig bounds how much history the TUI keeps in memory
+type ScrollbackConfig struct {
+	Lines     int    `json:"lines"`     // maximum lines per view before old ones are trimmed
+	History   int    `json:"history"`   // maximum events kept in the event store
+	SpillFile string `json:"spillFile"` // optional NDJSON file receiving evicted events
+}
+
+// DefaultScrollback keeps a long run readable without growing without bound
+var DefaultScrollback = ScrollbackConfig{
+	Lines:   5000,
+	History: 50000,
+}
+
+// spilledEvent is the NDJSON record written for each evicted event
+type spilledEvent struct {
+	Seq       int       `json:"seq"`
+	Time      time.Time `json:"time"`
+	Timestamp string    `json:"timestamp"`
+	Type      string    `json:"type"`
+	Level     string    `json:"level,omitempty"`
+	Phase     int       `json:"phase,omitempty"`
+	Tool      string    `json:"tool,omitempty"`
+	Content   string    `json:"content"`
+}
+
+// trimView drops old lines once a view goes over its cap. It keeps 90% of
+// the cap so the view is not rebuilt again on the very next line.
+func (t *TUI) trimView(mode string) {
+	p := t.projections[mode]
+	row, _ := p.view.GetScrollOffset()
+	_, _, _, height := p.view.GetInnerRect()
+	before := p.lines
+	t.layoutView(mode, t.config.Scrollback.Lines*9/10)
+	if row+height < before {
+		// Scrolled back: stay on the same lines, minus those dropped
+		p.view.ScrollTo(max(row-(before-p.lines), 0), 0)
+	}
+}
+
+// layoutView fills a view with the newest visible events that fit into
+// budget lines, preceded by a marker saying how much was dropped
+func (t *TUI) layoutView(mode string, budget int) {
+	p := t.projections[mode]
+
+	// Walk back from the newest event until the budget is used up; the
+	// older ones are only counted, not formatted
+	var shown []string
+	lines, skipped, first := 0, 0, 0
+	for i := t.events.Len() - 1; i >= 0; i-- {
+		e := t.events.At(i)
+		if !t.visible(p, e) {
+			continue
+		}
+		if skipped > 0 {
+			skipped++
+			continue
+		}
+		line := p.format(e)
+		n := strings.Count(line, "\n")
+		if lines+n > budget {
+			skipped++
+			continue
+		}
+		shown = append(shown, line)
+		lines += n
+		first = e.Seq
+	}
+
+	var builder strings.Builder
+	p.dropped = skipped
+	if marker := t.droppedMarker(p); marker != "" {
+		builder.WriteString(marker)
+		lines++
+	}
+	for i := len(shown) - 1; i >= 0; i-- {
+		builder.WriteString(shown[i])
+	}
+
+	p.lines = lines
+	p.firstSeq = first
+	p.view.SetText(builder.String())
+	p.view.ScrollToEnd()
+}
+
+// droppedMarker is the first line of a trimmed view
+func (t *TUI) droppedMarker(p *projection) string {
+	total := p.dropped + p.evicted
+	if total == 0 {
+		return ""
+	}
+	marker := fmt.Sprintf(t.theme.C("[muted]── %d earlier entries dropped"), total)
+	if t.spillFile != nil {
+		marker += fmt.Sprintf(", full history in %s", t.config.Scrollback.SpillFile)
+	}
+	return marker + t.theme.C(" ──[text]\n")
+}
+
+// evict is called by the event store for every event it pushes out. It
+// runs inside Append, so it must not record events itself.
+func (t *TUI) evict(e Event) {
+	for _, p := range t.projections {
+		if e.Seq > p.clearedAt && p.accepts(e) {
+			p.evicted++
+		}
+	}
+
+	if t.config.Scrollback.SpillFile == "" {
+		return
+	}
+	if t.spillFile == nil {
+		f, err := os.OpenFile(t.config.Scrollback.SpillFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			// Don't retry on every eviction
+			t.config.Scrollback.SpillFile = ""
+			// Logged by record once the evicting event is stored
+			t.spillErr = err
+			return
+		}
+		t.spillFile = f
+	}
+
+	data, err := json.Marshal(spilledEvent{
+		Seq:       e.Seq,
+		Time:      e.Time,
+		Timestamp: e.Timestamp,
+		Type:      e.Type,
+		Level:     e.Level,
+		Phase:     e.Phase,
+		Tool:      e.Tool,
+		Content:   e.Content,
+	})
+	if err == nil {
+		t.spillFile.Write(append(data, '\n'))
+	}
+}
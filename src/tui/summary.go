@@ -0,0 +1,310 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// RunSummary is the end-of-run report sent in a "summary" message. Fields the
+// agent leaves out are filled in from what the TUI counted itself.
+type RunSummary struct {
+	FilesDocumented int             `json:"filesDocumented"`
+	FilesFailed     int             `json:"filesFailed"`
+	Phases          []PhaseDuration `json:"phases"`
+	Warnings        int             `json:"warnings"`
+	Errors          int             `json:"errors"`
+	Tokens          TokenUsage      `json:"tokens"`
+	Cost            float64         `json:"cost"` // USD
+	OutputDir       string          `json:"outputDir"`
+	ElapsedSeconds  float64         `json:"elapsedSeconds"`
+	Written         []string        `json:"written,omitempty"` // every file the run wrote, for the review page
+
+	sent map[string]bool // JSON names of the fields the agent sent
+}
+
+// UnmarshalJSON notes which fields the agent sent, so a reported 0 isn't
+// taken for a missing count
+func (s *RunSummary) UnmarshalJSON(data []byte) error {
+	type plain RunSummary
+	if err := json.Unmarshal(data, (*plain)(s)); err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	s.sent = map[string]bool{}
+	for name, value := range fields {
+		if string(value) != "null" {
+			s.sent[name] = true
+		}
+	}
+	return nil
+}
+
+type PhaseDuration struct {
+	Name    string  `json:"name"`
+	Seconds float64 `json:"seconds"`
+}
+
+type TokenUsage struct {
+	Input  int `json:"input"`
+	Output int `json:"output"`
+}
+
+// runTally is what the TUI counts on its own while messages come in
+type runTally struct {
+	warnings   int
+	errors     int
+	phases     []PhaseDuration
+	phaseStart time.Time // start of the last entry of phases
+}
+
+// tally counts warnings and errors and times the phases of the run
+func (t *TUI) tally(msg Message) {
+	if msg.Type == "log" {
+		switch msg.Level {
+		case "warning":
+			t.runTally.warnings++
+		case "error":
+			t.runTally.errors++
+		}
+	}
+	if msg.Phase.Name == "" {
+		return
+	}
+	tally := &t.runTally
+	now := time.Now()
+	if n := len(tally.phases); n > 0 {
+		if tally.phases[n-1].Name == msg.Phase.Name {
+			return
+		}
+		tally.phases[n-1].Seconds = now.Sub(tally.phaseStart).Seconds()
+	}
+	tally.phases = append(tally.phases, PhaseDuration{Name: msg.Phase.Name})
+	tally.phaseStart = now
+}
+
+// runSummary merges the agent's summary, if one arrived, with the TUI's own counts
+func (t *TUI) runSummary() RunSummary {
+	s := RunSummary{}
+	if t.summary != nil {
+		s = *t.summary
+	}
+	// Only what the agent left out; the output directory can't be guessed
+	if !s.sent["filesDocumented"] {
+		s.FilesDocumented = t.files.Processed
+	}
+	if !s.sent["warnings"] {
+		s.Warnings = t.runTally.warnings
+	}
+	if !s.sent["errors"] {
+		s.Errors = t.runTally.errors
+	}
+
+	end := time.Now()
+	if !t.endTime.IsZero() {
+		end = t.endTime
+	}
+	if !s.sent["elapsedSeconds"] {
+		s.ElapsedSeconds = end.Sub(t.startTime).Seconds()
+	}
+	if !s.sent["phases"] && len(t.runTally.phases) > 0 {
+		s.Phases = append([]PhaseDuration(nil), t.runTally.phases...)
+		// The phase still open runs until the end of the run
+		s.Phases[len(s.Phases)-1].Seconds = end.Sub(t.runTally.phaseStart).Seconds()
+	}
+	return s
+}
+
+// noteSummary keeps a summary message and shows the results page
+func (t *TUI) noteSummary(s RunSummary) {
+	t.summary = &s
+	t.summaryShown = false
+	t.refreshSummary()
+	t.offerSummary()
+}
+
+// refreshSummary redraws the results page if it is open
+func (t *TUI) refreshSummary() {
+	if t.summaryView != nil {
+		t.summaryView.SetText(t.summaryText(t.runSummary()))
+	}
+}
+
+// offerSummary opens the results page once per run, or points at it if a
+// dialog is in the way
+func (t *TUI) offerSummary() {
+	if t.summaryShown {
+		return
+	}
+	t.summaryShown = true
+	if t.modalOpen {
+		t.showToast("Run summary ready, press " + t.keyHint("summary"))
+		return
+	}
+	t.showSummary()
+}
+
+// keyHint returns the first key of an action, for notices
+func (t *TUI) keyHint(id string) string {
+	if keys := t.actionKeys[id]; len(keys) > 0 {
+		return displayKey(keys[0])
+	}
+	return id
+}
+
+// summaryText renders the summary with theme tags for the results page
+func (t *TUI) summaryText(s RunSummary) string {
+	th := t.theme
+	icon, label := t.stateDisplay()
+	var b strings.Builder
+	fmt.Fprintf(&b, th.C("[label2]Project:[text]          %s\n"), tview.Escape(t.projectPath))
+	fmt.Fprintf(&b, th.C("[label2]Result:[text]           %s %s\n"), icon, label)
+	fmt.Fprintf(&b, th.C("[label2]Elapsed:[text]          %s\n\n"), formatElapsed(secondsDuration(s.ElapsedSeconds)))
+
+	fmt.Fprintf(&b, th.C("[label2]Files documented:[text] [success]%d[text]\n"), s.FilesDocumented)
+	failedRole := "text"
+	if s.FilesFailed > 0 {
+		failedRole = "error"
+	}
+	fmt.Fprintf(&b, th.C("[label2]Files failed:[text]     %s%d[text]\n"), th.Tag(failedRole), s.FilesFailed)
+	warningRole, errorRole := "text", "text"
+	if s.Warnings > 0 {
+		warningRole = "warning"
+	}
+	if s.Errors > 0 {
+		errorRole = "error"
+	}
+	fmt.Fprintf(&b, th.C("[label2]Warnings:[text]         %s%d[text]\n"), th.Tag(warningRole), s.Warnings)
+	fmt.Fprintf(&b, th.C("[label2]Errors:[text]           %s%d[text]\n\n"), th.Tag(errorRole), s.Errors)
+
+	if s.Tokens.Input > 0 || s.Tokens.Output > 0 {
+		fmt.Fprintf(&b, th.C("[label2]Tokens:[text]           %d in, %d out\n"), s.Tokens.Input, s.Tokens.Output)
+	} else {
+		b.WriteString(th.C("[label2]Tokens:[text]           [muted]not reported[text]\n"))
+	}
+	if s.Cost > 0 {
+		fmt.Fprintf(&b, th.C("[label2]Cost:[text]             $%.2f\n"), s.Cost)
+	} else {
+		b.WriteString(th.C("[label2]Cost:[text]             [muted]not reported[text]\n"))
+	}
+	outputDir := s.OutputDir
+	if outputDir == "" {
+		outputDir = th.C("[muted]unknown[text]")
+	} else {
+		outputDir = tview.Escape(outputDir)
+	}
+	fmt.Fprintf(&b, th.C("[label2]Output:[text]           %s\n"), outputDir)
+
+	if len(s.Phases) > 0 {
+		b.WriteString(th.C("\n[label]Phases[text]\n"))
+		for i, p := range s.Phases {
+			fmt.Fprintf(&b, th.C("  [label3]%d.[text] %-36s %s\n"),
+				i+1, tview.Escape(truncateMiddle(p.Name, 36)), formatElapsed(secondsDuration(p.Seconds)))
+		}
+	}
+	return b.String()
+}
+
+// summaryMarkdown renders the summary for an export
+func (t *TUI) summaryMarkdown(s RunSummary) string {
+	var b strings.Builder
+	b.WriteString("# docuMentor run summary\n\n")
+	fmt.Fprintf(&b, "- **Project:** %s\n", t.projectPath)
+	fmt.Fprintf(&b, "- **Result:** %s\n", runStateStyles[t.state].label)
+	fmt.Fprintf(&b, "- **Finished:** %s\n", time.Now().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "- **Elapsed:** %s\n", formatElapsed(secondsDuration(s.ElapsedSeconds)))
+	fmt.Fprintf(&b, "- **Files documented:** %d\n", s.FilesDocumented)
+	fmt.Fprintf(&b, "- **Files failed:** %d\n", s.FilesFailed)
+	fmt.Fprintf(&b, "- **Warnings:** %d\n", s.Warnings)
+	fmt.Fprintf(&b, "- **Errors:** %d\n", s.Errors)
+	fmt.Fprintf(&b, "- **Tokens:** %d in, %d out\n", s.Tokens.Input, s.Tokens.Output)
+	fmt.Fprintf(&b, "- **Cost:** $%.2f\n", s.Cost)
+	fmt.Fprintf(&b, "- **Output:** %s\n", s.OutputDir)
+	if len(s.Phases) > 0 {
+		b.WriteString("\n## Phases\n\n| # | Phase | Duration |\n|---|-------|----------|\n")
+		for i, p := range s.Phases {
+			fmt.Fprintf(&b, "| %d | %s | %s |\n", i+1, markdownCell(p.Name), formatElapsed(secondsDuration(p.Seconds)))
+		}
+	}
+	return b.String()
+}
+
+// exportSummary writes the summary as Markdown to the export directory
+func (t *TUI) exportSummary() (string, error) {
+	return writeNewFile(t.exportDir(), "documentor_summary", "md", []byte(t.summaryMarkdown(t.runSummary())))
+}
+
+func secondsDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// showSummary opens the full-screen results page
+func (t *TUI) showSummary() {
+	t.summaryShown = true
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(t.summaryText(t.runSummary()))
+	view.SetBorder(true).
+		SetBorderPadding(1, 1, 2, 2).
+		SetTitle(" Run Summary ").
+		SetTitleAlign(tview.AlignLeft)
+
+	keys := "[label]E[text] export as Markdown   "
+	if n := len(t.reviewFiles()); n > 0 {
+		keys += fmt.Sprintf("[label]I[text] review the %d written file(s)   ", n)
+	}
+	footer := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(t.theme.C(keys + "[label]Esc[text] back to the logs   [label]Q[text] quit"))
+	footer.SetBorder(true).
+		SetBorderPadding(0, 0, 1, 1)
+
+	page := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(view, 0, 1, true).
+		AddItem(footer, 3, 0, false)
+
+	t.summaryView = view
+	closeSummary := func() {
+		t.summaryView = nil
+		t.modalOpen = false
+		t.app.SetRoot(t.rootPages, true)
+		t.app.SetFocus(t.getCurrentView())
+	}
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc || event.Key() == tcell.KeyEnter || event.Rune() == 's' || event.Rune() == 'S':
+			closeSummary()
+			return nil
+		case event.Rune() == 'q' || event.Rune() == 'Q':
+			t.summaryView = nil
+			t.app.Stop()
+			return nil
+		case event.Rune() == 'i' || event.Rune() == 'I':
+			closeSummary()
+			t.showReview()
+			return nil
+		case event.Rune() == 'e' || event.Rune() == 'E':
+			path, err := t.exportSummary()
+			if err != nil {
+				footer.SetText(fmt.Sprintf(t.theme.C("[error]Export failed: %s[text]"), tview.Escape(err.Error())))
+				return nil
+			}
+			footer.SetText(fmt.Sprintf(t.theme.C("[success]Saved to[text] %s"), tview.Escape(path)))
+			t.addLog("success", fmt.Sprintf("Exported run summary to %s", path), time.Now().Format("15:04:05"))
+			return nil
+		}
+		return event
+	})
+
+	t.modalOpen = true
+	t.app.SetRoot(page, true)
+	t.app.SetFocus(view)
+}
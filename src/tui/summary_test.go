@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRunSummaryFallbacks(t *testing.T) {
+	tests := []struct {
+		name, summary     string // "" for no summary message
+		documented, warns int
+		errors            int
+		outputDir         string
+	}{
+		{"no summary", "", 7, 2, 1, ""},
+		{"nothing sent", `{}`, 7, 2, 1, ""},
+		{"null is not sent", `{"filesDocumented": null}`, 7, 2, 1, ""},
+		{"every doc rejected", `{"filesDocumented": 0, "warnings": 0, "errors": 0}`, 0, 0, 0, ""},
+		{"sent", `{"filesDocumented": 3, "warnings": 4, "errors": 5, "outputDir": "/vault/docs/p"}`, 3, 4, 5, "/vault/docs/p"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tui := NewTUI(DefaultConfig())
+			tui.projectPath = "/work/p"
+			tui.files.Processed = 7
+			tui.runTally.warnings, tui.runTally.errors = 2, 1
+			if tt.summary != "" {
+				var s RunSummary
+				if err := json.Unmarshal([]byte(tt.summary), &s); err != nil {
+					t.Fatal(err)
+				}
+				tui.summary = &s
+			}
+			s := tui.runSummary()
+			if s.FilesDocumented != tt.documented || s.Warnings != tt.warns || s.Errors != tt.errors || s.OutputDir != tt.outputDir {
+				t.Errorf("got %d documented, %d warnings, %d errors, output %q, want %d, %d, %d, %q",
+					s.FilesDocumented, s.Warnings, s.Errors, s.OutputDir, tt.documented, tt.warns, tt.errors, tt.outputDir)
+			}
+		})
+	}
+}
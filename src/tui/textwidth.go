@@ -0,0 +1,88 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// Text in the panels is measured in terminal cells, not bytes or runes:
+// East Asian wide characters take two cells and must never be cut in half.
+
+// truncateEnd shortens text to width cells, ending it with "…"
+func truncateEnd(text string, width int) string {
+	if width < 1 {
+		// runewidth keeps the "…" even where it doesn't fit
+		return ""
+	}
+	return runewidth.Truncate(text, width, "…")
+}
+
+// truncateMiddle shortens text to width cells, keeping both ends
+func truncateMiddle(text string, width int) string {
+	if runewidth.StringWidth(text) <= width {
+		return text
+	}
+	if width < 2 {
+		return truncateEnd(text, width)
+	}
+	head := runewidth.Truncate(text, (width-1)/2, "")
+	tail := truncateLeft(text, width-1-runewidth.StringWidth(head))
+	return head + "…" + tail
+}
+
+// truncateLeft keeps the last width cells of text
+func truncateLeft(text string, width int) string {
+	runes := []rune(text)
+	used, start := 0, len(runes)
+	for start > 0 {
+		w := runewidth.RuneWidth(runes[start-1])
+		if used+w > width {
+			break
+		}
+		used += w
+		start--
+	}
+	return string(runes[start:])
+}
+
+// padRight fills text up to width cells with spaces, truncating it first if
+// it is too wide. fmt's %-24s pads bytes and misaligns non-ASCII text.
+func padRight(text string, width int) string {
+	return runewidth.FillRight(truncateEnd(text, width), width)
+}
+
+// shortenPath fits a path into width cells by replacing directories in the
+// middle with "…", keeping the leading directory and the file name:
+// src/…/handlers/user.go. As a last resort it shortens the file name itself.
+func shortenPath(path string, width int) string {
+	if runewidth.StringWidth(path) <= width {
+		return path
+	}
+	parts := strings.Split(path, "/")
+	name := parts[len(parts)-1]
+	if len(parts) > 2 {
+		head := parts[0] + "/"
+		if parts[0] == "" && len(parts) > 3 {
+			// Absolute path, keep the first directory below /
+			head = "/" + parts[1] + "/"
+			parts = parts[1:]
+		}
+		// Add directories from the end while they fit
+		tail := name
+		for i := len(parts) - 2; i > 0; i-- {
+			longer := parts[i] + "/" + tail
+			if runewidth.StringWidth(head+"…/"+longer) > width {
+				break
+			}
+			tail = longer
+		}
+		if shortened := head + "…/" + tail; runewidth.StringWidth(shortened) <= width {
+			return shortened
+		}
+	}
+	if shortened := "…/" + name; len(parts) > 1 && runewidth.StringWidth(shortened) <= width {
+		return shortened
+	}
+	return truncateMiddle(name, width)
+}
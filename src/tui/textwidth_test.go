@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mattn/go-runewidth"
+)
+
+func TestTruncateMiddle(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  string
+	}{
+		{"", 5, ""},
+		{"short", 5, "short"},
+		{"short", 10, "short"},
+		{"abcdefghij", 5, "ab…ij"},
+		{"abcdefghij", 6, "ab…hij"},
+		{"abcdefghij", 2, "…j"},
+		{"abcdefghij", 1, "…"},
+		{"abcdefghij", 0, ""},
+		// Wide characters take two cells and are never cut in half
+		{"日本語のテキスト", 9, "日本…スト"},
+		{"日本語のテキスト", 8, "日…スト"},
+		{"ab日本語cd", 6, "ab…cd"},
+	}
+	for _, tt := range tests {
+		got := truncateMiddle(tt.text, tt.width)
+		if got != tt.want {
+			t.Errorf("truncateMiddle(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+		}
+		if w := runewidth.StringWidth(got); w > tt.width {
+			t.Errorf("truncateMiddle(%q, %d) is %d cells wide", tt.text, tt.width, w)
+		}
+	}
+}
+
+func TestShortenPath(t *testing.T) {
+	tests := []struct {
+		path  string
+		width int
+		want  string
+	}{
+		{"main.go", 20, "main.go"},
+		{"src/handlers/user.go", 20, "src/handlers/user.go"},
+		{"src/api/handlers/user.go", 22, "src/…/handlers/user.go"},
+		{"src/api/handlers/user.go", 20, "src/…/user.go"},
+		{"src/api/handlers/user.go", 12, "…/user.go"},
+		{"src/user.go", 10, "…/user.go"},
+		{"/home/me/project/docs/guide.md", 24, "/home/…/docs/guide.md"},
+		{"/home/me/project/docs/guide.md", 18, "/home/…/guide.md"},
+		{"/home/me/project/docs/guide.md", 12, "…/guide.md"},
+		{"docs/a-very-long-file-name.md", 12, "a-ver…ame.md"},
+		{"a-very-long-file-name.md", 12, "a-ver…ame.md"},
+		{"a-very-long-file-name.md", 0, ""},
+		{"文書/ガイド/日本語.md", 16, "文書/…/日本語.md"},
+		{"文書/ガイド/日本語.md", 15, "…/日本語.md"},
+	}
+	for _, tt := range tests {
+		got := shortenPath(tt.path, tt.width)
+		if got != tt.want {
+			t.Errorf("shortenPath(%q, %d) = %q, want %q", tt.path, tt.width, got, tt.want)
+		}
+		if w := runewidth.StringWidth(got); w > tt.width {
+			t.Errorf("shortenPath(%q, %d) is %d cells wide", tt.path, tt.width, w)
+		}
+	}
+}
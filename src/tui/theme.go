@@ -0,0 +1,359 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// themeRoles lists the semantic colors a theme defines. Values are tview
+// color specs ("fg", "fg:bg" or "fg:bg:attrs"), so a role can also carry
+// attributes, which is how the monochrome theme marks emphasis. Color names
+// are tcell's (aqua and fuchsia, not cyan and magenta) or #rrggbb.
+var themeRoles = []string{
+	"text",               // default text; also used to reset after other roles
+	"muted",              // timestamps, hints
+	"dim",                // debug and raw lines
+	"accent",             // headers, emphasis
+	"info",               // info level
+	"success",            // success level, progress
+	"warning",            // warning level
+	"error",              // error level
+	"tool",               // tool calls
+	"label",              // first info box column labels
+	"label2",             // second info box column labels
+	"label3",             // third info box column labels
+	"header",             // title bar
+	"badge",              // filter badge
+	"toast",              // footer notices
+	"background",         // screen background
+	"field",              // form input fields
+	"border",             // panel borders
+	"title",              // panel titles
+	"button",             // button background
+	"buttonText",         // button label
+	"buttonActive",       // background of the button for the current view
+	"buttonActiveText",   // its label
+	"buttonSelected",     // background of the keyboard-selected button
+	"buttonSelectedText", // its label
+	"buttonBorder",
+}
+
+// builtinThemes are the themes available without any config
+var builtinThemes = map[string]map[string]string{
+	"dark": {
+		"text":               "white::-",
+		"muted":              "gray",
+		"dim":                "-::d",
+		"accent":             "aqua",
+		"info":               "aqua",
+		"success":            "green",
+		"warning":            "yellow",
+		"error":              "red",
+		"tool":               "yellow",
+		"label":              "yellow",
+		"label2":             "aqua",
+		"label3":             "fuchsia",
+		"header":             "white::b",
+		"badge":              "black:yellow",
+		"toast":              "black:green",
+		"background":         "black",
+		"field":              "blue",
+		"border":             "white",
+		"title":              "white",
+		"button":             "darkgray",
+		"buttonText":         "yellow::b",
+		"buttonActive":       "darkcyan",
+		"buttonActiveText":   "white::b",
+		"buttonSelected":     "lightblue",
+		"buttonSelectedText": "black::b",
+		"buttonBorder":       "lightgray",
+	},
+	"light": {
+		"text":               "black::-",
+		"muted":              "#585858",
+		"dim":                "#6c6c6c",
+		"accent":             "#005f87",
+		"info":               "#005f87",
+		"success":            "#006400",
+		"warning":            "#875f00",
+		"error":              "#af0000",
+		"tool":               "#875f00",
+		"label":              "#875f00",
+		"label2":             "#005f87",
+		"label3":             "#870087",
+		"header":             "black::b",
+		"badge":              "black:#ffd75f",
+		"toast":              "white:#006400",
+		"background":         "white",
+		"field":              "#d0d0d0",
+		"border":             "#808080",
+		"title":              "black",
+		"button":             "#d0d0d0",
+		"buttonText":         "#5f3700::b",
+		"buttonActive":       "#87d7ff",
+		"buttonActiveText":   "black::b",
+		"buttonSelected":     "#005f87",
+		"buttonSelectedText": "white::b",
+		"buttonBorder":       "#808080",
+	},
+	"high-contrast": {
+		"text":               "white::-",
+		"muted":              "silver",
+		"dim":                "silver",
+		"accent":             "aqua::b",
+		"info":               "aqua",
+		"success":            "lime",
+		"warning":            "yellow::b",
+		"error":              "#ff5f5f::b",
+		"tool":               "yellow",
+		"label":              "yellow::b",
+		"label2":             "aqua::b",
+		"label3":             "fuchsia::b",
+		"header":             "white::bu",
+		"badge":              "black:yellow:b",
+		"toast":              "black:lime:b",
+		"background":         "black",
+		"field":              "navy",
+		"border":             "white",
+		"title":              "yellow",
+		"button":             "black",
+		"buttonText":         "yellow::b",
+		"buttonActive":       "blue",
+		"buttonActiveText":   "white::b",
+		"buttonSelected":     "yellow",
+		"buttonSelectedText": "black::b",
+		"buttonBorder":       "white",
+	},
+	"solarized": {
+		"text":               "#93a1a1::-",
+		"muted":              "#586e75",
+		"dim":                "#657b83",
+		"accent":             "#2aa198",
+		"info":               "#268bd2",
+		"success":            "#859900",
+		"warning":            "#b58900",
+		"error":              "#dc322f",
+		"tool":               "#cb4b16",
+		"label":              "#b58900",
+		"label2":             "#2aa198",
+		"label3":             "#d33682",
+		"header":             "#eee8d5::b",
+		"badge":              "#002b36:#b58900",
+		"toast":              "#002b36:#859900",
+		"background":         "#002b36",
+		"field":              "#073642",
+		"border":             "#586e75",
+		"title":              "#93a1a1",
+		"button":             "#073642",
+		"buttonText":         "#b58900::b",
+		"buttonActive":       "#2aa198",
+		"buttonActiveText":   "#002b36::b",
+		"buttonSelected":     "#268bd2",
+		"buttonSelectedText": "#fdf6e3::b",
+		"buttonBorder":       "#586e75",
+	},
+	// Used when NO_COLOR is set: terminal default colors, attributes only
+	"mono": {
+		"text":               "-::-",
+		"muted":              "-::d",
+		"dim":                "-::d",
+		"accent":             "-::b",
+		"info":               "-",
+		"success":            "-",
+		"warning":            "-::b",
+		"error":              "-::bu",
+		"tool":               "-",
+		"label":              "-::b",
+		"label2":             "-::b",
+		"label3":             "-::b",
+		"header":             "-::b",
+		"badge":              "-:-:r",
+		"toast":              "-:-:r",
+		"background":         "default",
+		"field":              "default",
+		"border":             "default",
+		"title":              "default",
+		"button":             "default",
+		"buttonText":         "-::b",
+		"buttonActive":       "default",
+		"buttonActiveText":   "-::bu",
+		"buttonSelected":     "default",
+		"buttonSelectedText": "-::r",
+		"buttonBorder":       "default",
+	},
+}
+
+// ThemeConfig defines a user theme in the config file
+type ThemeConfig struct {
+	Base   string            `json:"base"`   // built-in theme to start from, default dark
+	Colors map[string]string `json:"colors"` // role -> color spec
+}
+
+// Theme maps semantic roles onto tview color specs
+type Theme struct {
+	Name     string
+	roles    map[string]string
+	replacer *strings.Replacer
+}
+
+// newTheme builds a theme from a role map
+func newTheme(name string, roles map[string]string) *Theme {
+	th := &Theme{Name: name, roles: roles}
+	var pairs []string
+	for role, spec := range roles {
+		pairs = append(pairs, "["+role+"]", "["+spec+"]")
+	}
+	th.replacer = strings.NewReplacer(pairs...)
+	return th
+}
+
+// C replaces role tags such as [error] or [muted] in a format string with
+// the theme's color tags. Apply it to format strings, not to content.
+func (th *Theme) C(format string) string {
+	return th.replacer.Replace(format)
+}
+
+// Tag returns the color tag of a role
+func (th *Theme) Tag(role string) string {
+	return "[" + th.roles[role] + "]"
+}
+
+// Color returns the foreground color of a role, for widget colors
+func (th *Theme) Color(role string) tcell.Color {
+	fg := strings.SplitN(th.roles[role], ":", 2)[0]
+	if fg == "" || fg == "-" {
+		return tcell.ColorDefault
+	}
+	return tcell.GetColor(fg)
+}
+
+// Apply sets tview's default styles so widgets created afterwards use the theme
+func (th *Theme) Apply() {
+	tview.Styles.PrimitiveBackgroundColor = th.Color("background")
+	tview.Styles.ContrastBackgroundColor = th.Color("field")
+	tview.Styles.MoreContrastBackgroundColor = th.Color("button")
+	tview.Styles.BorderColor = th.Color("border")
+	tview.Styles.TitleColor = th.Color("title")
+	tview.Styles.GraphicsColor = th.Color("border")
+	tview.Styles.PrimaryTextColor = th.Color("text")
+	tview.Styles.SecondaryTextColor = th.Color("label")
+	tview.Styles.TertiaryTextColor = th.Color("success")
+	tview.Styles.InverseTextColor = th.Color("accent")
+	tview.Styles.ContrastSecondaryTextColor = th.Color("label2")
+}
+
+// validColorSpec reports whether every color in a "fg:bg:attrs" spec is known
+func validColorSpec(spec string) bool {
+	parts := strings.Split(spec, ":")
+	if len(parts) > 3 {
+		return false
+	}
+	for i, part := range parts {
+		if i == 2 || part == "" || part == "-" || part == "default" {
+			continue
+		}
+		if tcell.GetColor(part) == tcell.ColorDefault {
+			return false
+		}
+	}
+	return true
+}
+
+// themeNames lists the built-in and user theme names for error messages
+func (c *Config) themeNames() []string {
+	var names []string
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	for name := range c.Themes {
+		if _, builtin := builtinThemes[name]; !builtin {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateTheme checks the theme settings, dropping anything invalid
+func (c *Config) validateTheme() []error {
+	var errs []error
+
+	checkColors := func(where string, colors map[string]string) {
+		var roles []string
+		for role := range colors {
+			roles = append(roles, role)
+		}
+		sort.Strings(roles)
+		for _, role := range roles {
+			spec := colors[role]
+			if !containsString(themeRoles, role) {
+				errs = append(errs, fmt.Errorf("%s.%s: unknown color role", where, role))
+				delete(colors, role)
+			} else if !validColorSpec(spec) {
+				errs = append(errs, fmt.Errorf("%s.%s: unknown color %q", where, role, spec))
+				delete(colors, role)
+			}
+		}
+	}
+
+	for name, custom := range c.Themes {
+		if custom.Base != "" {
+			if _, ok := builtinThemes[custom.Base]; !ok {
+				errs = append(errs, fmt.Errorf("themes.%s.base: unknown built-in theme %q", name, custom.Base))
+				custom.Base = ""
+				c.Themes[name] = custom
+			}
+		}
+		checkColors("themes."+name+".colors", custom.Colors)
+	}
+	checkColors("colors", c.Colors)
+
+	if _, builtin := builtinThemes[c.Theme]; !builtin {
+		if _, custom := c.Themes[c.Theme]; !custom {
+			errs = append(errs, fmt.Errorf("theme: unknown theme %q, available: %s",
+				c.Theme, strings.Join(c.themeNames(), ", ")))
+			c.Theme = DefaultConfig().Theme
+		}
+	}
+	return errs
+}
+
+// ResolveTheme builds the active theme: NO_COLOR wins, then the configured
+// theme (a user theme on top of its base), then the color overrides
+func (c *Config) ResolveTheme() *Theme {
+	name := c.Theme
+	if os.Getenv("NO_COLOR") != "" {
+		name = "mono"
+	}
+
+	roles := map[string]string{}
+	base, custom := name, ThemeConfig{}
+	if user, ok := c.Themes[name]; ok && name != "mono" {
+		custom = user
+		base = user.Base
+		if _, builtin := builtinThemes[name]; builtin && base == "" {
+			// A user theme named like a built-in one amends it
+			base = name
+		}
+	}
+	if _, ok := builtinThemes[base]; !ok {
+		base = "dark"
+	}
+	for role, spec := range builtinThemes[base] {
+		roles[role] = spec
+	}
+	for role, spec := range custom.Colors {
+		roles[role] = spec
+	}
+	if name != "mono" {
+		for role, spec := range c.Colors {
+			roles[role] = spec
+		}
+	}
+	return newTheme(name, roles)
+}
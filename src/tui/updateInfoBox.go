@@ -18,25 +18,22 @@ func (t *TUI) updateInfoBox() {
 			projectName = name
 		}
 	}
-	// Truncate if too long
-	if len(projectName) > 24 {
-		projectName = projectName[:21] + "..."
-	}
+	projectName = shortenPath(projectName, 24)
 	
 	// Lock status
 	lockStatus := "unlocked"
-	lockColor := "green"
+	lockRole := "success"
 	if t.lockInfo.Status == "locked" {
 		if t.lockInfo.Resuming {
 			lockStatus = "resuming"
-			lockColor = "yellow"
+			lockRole = "warning"
 		} else {
 			lockStatus = "locked"
-			lockColor = "yellow"
+			lockRole = "warning"
 		}
 	} else if t.lockInfo.Status == "stale" {
 		lockStatus = "stale"
-		lockColor = "red"
+		lockRole = "error"
 	}
 	
 	// Phase info
@@ -44,20 +41,14 @@ func (t *TUI) updateInfoBox() {
 	if t.phase.Current == 0 && t.phase.Total == 0 && t.phase.Name == "" {
 		phaseInfo = "idle"
 	}
-	// Truncate if too long
-	if len(phaseInfo) > 24 {
-		phaseInfo = phaseInfo[:21] + "..."
-	}
+	phaseInfo = truncateEnd(phaseInfo, 24)
 	
 	// Task info (using SubPhase)
 	taskInfo := "idle"
 	if t.phase.SubPhase != "" {
 		taskInfo = t.phase.SubPhase
 	}
-	// Truncate if too long
-	if len(taskInfo) > 27 {
-		taskInfo = taskInfo[:24] + "..."
-	}
+	taskInfo = truncateEnd(taskInfo, 27)
 	
 	// Files counter
 	filesInfo := fmt.Sprintf("%d/%d", t.files.Processed, t.files.Total)
@@ -71,46 +62,56 @@ func (t *TUI) updateInfoBox() {
 	// Build layout using a table-like structure with FIXED columns
 	// We'll use padding to ensure consistent positioning
 	
+	th := t.theme
 	var builder strings.Builder
 	
 	// Line 1: project | pid | files
 	// Fixed layout: label(8) + value(24) + spacing(3) = 35 chars per column
-	builder.WriteString("[yellow]project:[white] ")
-	builder.WriteString(fmt.Sprintf("%-24s", projectName))
-	builder.WriteString(" [yellow]pid:[white] ")
+	builder.WriteString(th.C("[label]project:[text] "))
+	builder.WriteString(padRight(projectName, 24))
+	builder.WriteString(th.C(" [label]pid:[text] "))
 	builder.WriteString(fmt.Sprintf("%-8d", t.pid))
-	builder.WriteString(" [yellow]files:[white] ")
+	builder.WriteString(th.C(" [label]files:[text] "))
 	builder.WriteString(filesInfo)
 	builder.WriteString("\n")
 	
 	// Line 2: phase | task
-	builder.WriteString("[cyan]phase:[white]   ")
-	builder.WriteString(fmt.Sprintf("%-24s", phaseInfo))
-	builder.WriteString(" [cyan]task:[white] ")
-	builder.WriteString(fmt.Sprintf("%-27s", taskInfo))
+	builder.WriteString(th.C("[label2]phase:[text]   "))
+	builder.WriteString(padRight(phaseInfo, 24))
+	builder.WriteString(th.C(" [label2]task:[text] "))
+	builder.WriteString(padRight(taskInfo, 27))
 	builder.WriteString("\n")
 	
 	// Line 3: lockfile | status
-	builder.WriteString("[magenta]lockfile:[white] ")
+	builder.WriteString(th.C("[label3]lockfile:[text] "))
 	builder.WriteString(fmt.Sprintf("%-23s", lockfileTime))
-	builder.WriteString(" [")
-	builder.WriteString(lockColor)
-	builder.WriteString("]")
+	builder.WriteString(" ")
+	builder.WriteString(th.Tag(lockRole))
 	builder.WriteString(fmt.Sprintf("%-12s", lockStatus))
-	builder.WriteString("[white]")
+	builder.WriteString(th.Tag("text"))
+	
+	// Problem counters, dimmed while there are none
+	warningRole, errorRole := "muted", "muted"
+	if t.problems.warnings > 0 {
+		warningRole = "warning"
+	}
+	if t.problems.errors > 0 {
+		errorRole = "error"
+	}
+	builder.WriteString(th.C("[label3]problems:[text] "))
+	builder.WriteString(fmt.Sprintf("%s\uf071 %d%s  %s\uf00d %d%s",
+		th.Tag(warningRole), t.problems.warnings, th.Tag("text"),
+		th.Tag(errorRole), t.problems.errors, th.Tag("text")))
 	
 	info := builder.String()
 	
 	// Add process stats in debug mode
 	if t.viewMode == "debug" {
-		info += fmt.Sprintf("\n\n[gray]═══ Process Stats ═══[white]\n"+
-			"[cyan]Memory:[white] %-10s [cyan]Threads:[white] %-8d\n"+
-			"[cyan]CPU:[white]    %-10s",
-			fmt.Sprintf("%dMB", t.processStats.MemoryMB),
-			t.processStats.Goroutines,
-			fmt.Sprintf("%.1f%%", t.processStats.CPUPercent),
-		)
+		info += th.C("\n\n[muted]═══ Process Stats ═══[text]\n") + t.agentDetails()
+		info += fmt.Sprintf(th.C("\n[label2]TUI:[text]    %dMB heap, %d goroutines"),
+			t.processStats.MemoryMB, t.processStats.Goroutines)
 	}
 	
 	t.infoBox.SetText(info)
+	t.updateStatusLine()
 }
\ No newline at end of file
@@ -0,0 +1,702 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// maxVaultNotes is how many notes of the output folder the vault browser
+// reads, maxVaultFiles how many files of the vault it indexes to resolve
+// links
+const (
+	maxVaultNotes = 2000
+	maxVaultFiles = 50000
+)
+
+// vaultNote is a Markdown note of the output folder
+type vaultNote struct {
+	path      string // relative to the vault, with the extension
+	title     string
+	tags      []string
+	aliases   []string
+	links     []vaultLink
+	backlinks []string // notes of the output folder linking here
+}
+
+// vaultLink is a [[wiki link]] or ![[embed]] of a note
+type vaultLink struct {
+	raw    string // between the brackets
+	target string // the file it resolves to, relative to the vault, "" if broken
+	embed  bool
+}
+
+// vault is the output folder and the Obsidian vault it lives in
+type vault struct {
+	root      string                // the nearest directory with .obsidian, or the output folder
+	dir       string                // the output folder, relative to root, "." for root itself
+	notes     map[string]*vaultNote // notes of the output folder by path
+	order     []string              // their paths, sorted
+	files     map[string]string     // lower case path, and for notes the path without .md, to path
+	names     map[string][]string   // lower case base name, notes without .md, to paths
+	truncated bool                  // the vault had more files or notes than read
+}
+
+// Obsidian flavored Markdown
+var (
+	wikiLink   = regexp.MustCompile(`(!?)\[\[([^\[\]\n]+)\]\]`)
+	inlineTag  = regexp.MustCompile(`(^|\s)#([\p{L}\p{N}_/-]+)`)
+	inlineCode = regexp.MustCompile("`[^`]*`")
+)
+
+// vaultDir returns the output folder: the summary's, or else the folder
+// holding every Markdown file the run wrote
+func (t *TUI) vaultDir() string {
+	if t.summary != nil && t.summary.OutputDir != "" {
+		return t.resolvePath(t.summary.OutputDir)
+	}
+	var dirs []string
+	for _, file := range t.reviewFiles() {
+		if docExtensions[strings.ToLower(filepath.Ext(file))] {
+			dirs = append(dirs, filepath.Dir(t.resolvePath(file)))
+		}
+	}
+	return commonDir(dirs)
+}
+
+// commonDir returns the deepest directory holding all of dirs, or "" if
+// that is only the working directory or the root, or there is none, as for
+// relative and absolute paths mixed
+func commonDir(dirs []string) string {
+	dir := ""
+	for _, d := range dirs {
+		d = filepath.Clean(d)
+		if dir == "" {
+			dir = d
+		}
+		for {
+			if rel, err := filepath.Rel(dir, d); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				break
+			}
+			if filepath.Dir(dir) == dir || filepath.Base(dir) == ".." {
+				// Nothing above "." or the root, and ".." can't be
+				// climbed without knowing the working directory
+				return ""
+			}
+			dir = filepath.Dir(dir)
+		}
+	}
+	if dir == "." || filepath.Dir(dir) == dir {
+		return ""
+	}
+	return dir
+}
+
+// vaultRoot returns the nearest directory holding an .obsidian folder, or
+// dir itself if there is none
+func vaultRoot(dir string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		if info, err := os.Stat(filepath.Join(d, ".obsidian")); err == nil && info.IsDir() {
+			return d
+		}
+		if filepath.Dir(d) == d {
+			return dir
+		}
+	}
+}
+
+// scanVault indexes the vault around dir and reads the notes of dir
+func scanVault(dir string) (*vault, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(dir); err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	v := &vault{
+		root:  vaultRoot(dir),
+		notes: map[string]*vaultNote{},
+		files: map[string]string{},
+		names: map[string][]string{},
+	}
+	v.dir, _ = filepath.Rel(v.root, dir)
+	v.dir = filepath.ToSlash(v.dir)
+
+	var notes []string
+	err = filepath.WalkDir(v.root, func(abs string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			// .obsidian, .git, .trash and the like
+			if abs != v.root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if len(v.files) >= maxVaultFiles {
+			v.truncated = true
+			return filepath.SkipAll
+		}
+		rel, _ := filepath.Rel(v.root, abs)
+		rel = filepath.ToSlash(rel)
+		name := strings.ToLower(path.Base(rel))
+		v.files[strings.ToLower(rel)] = rel
+		if strings.EqualFold(path.Ext(rel), ".md") {
+			v.files[strings.ToLower(strings.TrimSuffix(rel, path.Ext(rel)))] = rel
+			name = strings.TrimSuffix(name, ".md")
+			if v.dir == "." || strings.HasPrefix(rel, v.dir+"/") {
+				notes = append(notes, rel)
+			}
+		}
+		v.names[name] = append(v.names[name], rel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(notes) > maxVaultNotes {
+		notes, v.truncated = notes[:maxVaultNotes], true
+	}
+	for _, rel := range notes {
+		data, err := readHead(filepath.Join(v.root, filepath.FromSlash(rel)), maxPreviewBytes)
+		if err != nil {
+			continue
+		}
+		v.notes[rel] = v.parseNote(rel, string(data))
+		v.order = append(v.order, rel)
+	}
+	sort.Strings(v.order)
+
+	// Backlinks, once every note is read
+	for _, from := range v.order {
+		for _, link := range v.notes[from].links {
+			to, ok := v.notes[link.target]
+			if ok && link.target != from && !containsString(to.backlinks, from) {
+				to.backlinks = append(to.backlinks, from)
+			}
+		}
+	}
+	return v, nil
+}
+
+// parseNote reads the title, tags, aliases and links of a note
+func (v *vault) parseNote(rel, text string) *vaultNote {
+	front, body := splitFrontmatter(text)
+	n := &vaultNote{path: rel}
+	n.title = frontmatterScalar(front, "title")
+	n.tags = append(frontmatterList(front, "tags"), frontmatterList(front, "tag")...)
+	n.aliases = append(frontmatterList(front, "aliases"), frontmatterList(front, "alias")...)
+	for i, tag := range n.tags {
+		n.tags[i] = strings.TrimPrefix(tag, "#")
+	}
+
+	seen := map[string]bool{}
+	inCode := false
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+		if n.title == "" {
+			if m := mdHeading.FindStringSubmatch(line); m != nil && len(m[1]) == 1 {
+				n.title = strings.TrimSpace(m[2])
+			}
+		}
+		line = inlineCode.ReplaceAllString(line, "")
+		for _, m := range wikiLink.FindAllStringSubmatch(line, -1) {
+			// In tables the alias pipe is escaped
+			raw := strings.ReplaceAll(m[2], `\|`, "|")
+			if key := strings.ToLower(m[1] + raw); !seen[key] {
+				seen[key] = true
+				n.links = append(n.links, vaultLink{raw: raw, target: v.resolve(rel, raw), embed: m[1] == "!"})
+			}
+		}
+		for _, m := range inlineTag.FindAllStringSubmatch(line, -1) {
+			// A tag needs something besides digits: #123 is not one
+			if strings.Trim(m[2], "0123456789") != "" {
+				n.tags = append(n.tags, m[2])
+			}
+		}
+	}
+	if n.title == "" {
+		n.title = strings.TrimSuffix(path.Base(rel), path.Ext(rel))
+	}
+	n.tags = uniqueFold(n.tags)
+	return n
+}
+
+// resolve finds the file a link points to the way Obsidian does: by path
+// from the vault root, by path from the note's folder, or by name,
+// preferring the note's folder and then the shortest path
+func (v *vault) resolve(from, raw string) string {
+	target := raw
+	if i := strings.Index(target, "|"); i >= 0 {
+		target = target[:i]
+	}
+	if i := strings.IndexAny(target, "#^"); i >= 0 {
+		target = target[:i]
+	}
+	target = strings.TrimSpace(target)
+	if target == "" {
+		// [[#Heading]] links into the note itself
+		return from
+	}
+	lower := strings.ToLower(strings.TrimPrefix(target, "/"))
+	if rel, ok := v.files[lower]; ok {
+		return rel
+	}
+	if rel, ok := v.files[strings.ToLower(path.Join(path.Dir(from), target))]; ok {
+		return rel
+	}
+	best := ""
+	for _, rel := range v.names[strings.TrimSuffix(path.Base(lower), ".md")] {
+		candidate := strings.ToLower(rel)
+		if candidate != lower && !strings.HasSuffix(candidate, "/"+lower) {
+			candidate = strings.TrimSuffix(candidate, ".md")
+			if candidate != lower && !strings.HasSuffix(candidate, "/"+lower) {
+				continue
+			}
+		}
+		switch {
+		case best == "":
+			best = rel
+		case path.Dir(rel) == path.Dir(from) && path.Dir(best) != path.Dir(from):
+			best = rel
+		case path.Dir(best) != path.Dir(from) && len(rel) < len(best):
+			best = rel
+		}
+	}
+	return best
+}
+
+// broken counts the links of a note that resolve to nothing
+func (n *vaultNote) broken() int {
+	count := 0
+	for _, link := range n.links {
+		if link.target == "" {
+			count++
+		}
+	}
+	return count
+}
+
+// frontmatterScalar returns a top level value of the frontmatter, unquoted
+func frontmatterScalar(front, key string) string {
+	for _, line := range strings.Split(front, "\n") {
+		if value, ok := strings.CutPrefix(line, key+":"); ok {
+			return strings.Trim(strings.TrimSpace(value), `"'`)
+		}
+	}
+	return ""
+}
+
+// frontmatterList returns a top level list of the frontmatter, written as
+// [a, b], as "- a" lines below the key, or as one string of words
+func frontmatterList(front, key string) []string {
+	var items []string
+	lines := strings.Split(front, "\n")
+	for i, line := range lines {
+		value, ok := strings.CutPrefix(line, key+":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch {
+		case strings.HasPrefix(value, "["):
+			items = strings.Split(strings.Trim(value, "[]"), ",")
+		case value != "":
+			items = append(items, strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' })...)
+		default:
+			for _, next := range lines[i+1:] {
+				item, ok := strings.CutPrefix(strings.TrimSpace(next), "- ")
+				if !ok || !strings.HasPrefix(next, " ") && !strings.HasPrefix(next, "-") {
+					break
+				}
+				items = append(items, item)
+			}
+		}
+		break
+	}
+	var clean []string
+	for _, item := range items {
+		if item = strings.Trim(strings.TrimSpace(item), `"'`); item != "" {
+			clean = append(clean, item)
+		}
+	}
+	return clean
+}
+
+// uniqueFold drops repeated strings, ignoring case, keeping the first
+func uniqueFold(list []string) []string {
+	seen := map[string]bool{}
+	var unique []string
+	for _, s := range list {
+		if key := strings.ToLower(s); !seen[key] {
+			seen[key] = true
+			unique = append(unique, s)
+		}
+	}
+	return unique
+}
+
+// vaultFilters narrow the note list to the notes needing a look
+var vaultFilters = []struct {
+	name string
+	keep func(n *vaultNote) bool
+}{
+	{"all notes", func(n *vaultNote) bool { return true }},
+	{"broken links", func(n *vaultNote) bool { return n.broken() > 0 }},
+	{"no backlinks", func(n *vaultNote) bool { return len(n.backlinks) == 0 }},
+	{"no tags", func(n *vaultNote) bool { return len(n.tags) == 0 }},
+}
+
+// vaultBrowser is the open vault page
+type vaultBrowser struct {
+	vault    *vault
+	notes    *tview.List
+	header   *tview.TextView
+	links    *tview.List
+	body     *tview.TextView
+	right    *tview.Pages
+	footer   *tview.TextView
+	page     *tview.Flex
+	shown    []string // notes in the list, after the filter
+	targets  []string // file each item of the links list goes to, "" if broken
+	current  string   // the note shown
+	history  []string // notes followed from, for going back
+	filter   int
+	showBody bool // the rendered note is shown instead of its links
+}
+
+// showVault opens the vault browser on the output folder
+func (t *TUI) showVault() {
+	dir := t.vaultDir()
+	if dir == "" {
+		t.showToast("No output folder: no docs written yet, or no folder holds them all")
+		return
+	}
+	v, err := scanVault(dir)
+	if err != nil {
+		t.showToast("Cannot read the vault: " + err.Error())
+		return
+	}
+	if len(v.order) == 0 {
+		t.showToast("No notes in " + dir)
+		return
+	}
+	th := t.theme
+	b := &vaultBrowser{
+		vault:  v,
+		notes:  tview.NewList().ShowSecondaryText(false).SetHighlightFullLine(true),
+		header: tview.NewTextView().SetDynamicColors(true).SetWrap(true),
+		links:  tview.NewList().ShowSecondaryText(false).SetHighlightFullLine(true),
+		body: tview.NewTextView().
+			SetDynamicColors(true).
+			SetScrollable(true).
+			SetWrap(true).
+			SetWordWrap(true),
+		right:  tview.NewPages(),
+		footer: tview.NewTextView().SetDynamicColors(true),
+	}
+	for _, list := range []*tview.List{b.notes, b.links} {
+		list.SetBorder(true).
+			SetTitleAlign(tview.AlignLeft)
+		list.SetMainTextColor(th.Color("text")).
+			SetSelectedBackgroundColor(th.Color("accent"))
+	}
+	b.notes.SetChangedFunc(func(index int, _, _ string, _ rune) {
+		if index < len(b.shown) {
+			t.loadNote(b, b.shown[index])
+		}
+	})
+	b.header.SetBorder(true).
+		SetBorderPadding(0, 0, 1, 1).
+		SetTitleAlign(tview.AlignLeft)
+	b.body.SetBorder(true).
+		SetBorderPadding(0, 0, 1, 1).
+		SetTitle(" note ").
+		SetTitleAlign(tview.AlignLeft)
+	b.footer.SetBorder(true).
+		SetBorderPadding(0, 0, 1, 1)
+	b.right.AddPage("links", b.links, true, true).
+		AddPage("body", b.body, true, false)
+
+	listWidth := filesWidth
+	if t.layout != "full" {
+		listWidth = filesWidthCompact
+	}
+	b.page = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tview.NewFlex().
+			AddItem(b.notes, listWidth, 0, true).
+			AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+				AddItem(b.header, 7, 0, false).
+				AddItem(b.right, 0, 1, false), 0, 1, false), 0, 1, true).
+		AddItem(b.footer, 3, 0, false)
+
+	b.page.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc:
+			t.modalOpen = false
+			t.app.SetRoot(t.rootPages, true)
+			t.app.SetFocus(t.getCurrentView())
+		case event.Key() == tcell.KeyTab || event.Key() == tcell.KeyBacktab:
+			if b.notes.HasFocus() {
+				t.app.SetFocus(b.right)
+			} else {
+				t.app.SetFocus(b.notes)
+			}
+		case event.Key() == tcell.KeyEnter && b.notes.HasFocus():
+			t.app.SetFocus(b.right)
+		case event.Key() == tcell.KeyEnter && b.links.HasFocus():
+			if i := b.links.GetCurrentItem(); i < len(b.targets) {
+				t.followLink(b, b.targets[i])
+			}
+		case event.Key() == tcell.KeyBackspace || event.Key() == tcell.KeyBackspace2:
+			if len(b.history) == 0 {
+				t.showToast("No link followed yet")
+				break
+			}
+			back := b.history[len(b.history)-1]
+			b.history = b.history[:len(b.history)-1]
+			t.selectNote(b, back)
+		case event.Rune() == 'q' || event.Rune() == 'Q':
+			t.app.Stop()
+		case event.Rune() == 'f' || event.Rune() == 'F':
+			b.filter = (b.filter + 1) % len(vaultFilters)
+			t.updateVaultList(b)
+		case event.Rune() == 'p' || event.Rune() == 'P':
+			b.showBody = !b.showBody
+			t.loadNote(b, b.current)
+			t.app.SetFocus(b.right)
+		case event.Rune() == 'r' || event.Rune() == 'R':
+			v, err := scanVault(filepath.Join(b.vault.root, filepath.FromSlash(b.vault.dir)))
+			if err != nil {
+				t.showToast("Cannot read the vault: " + err.Error())
+				break
+			}
+			b.vault = v
+			t.updateVaultList(b)
+		default:
+			return event
+		}
+		t.updateVaultFooter(b)
+		return nil
+	})
+
+	t.updateVaultList(b)
+	t.updateVaultFooter(b)
+	t.modalOpen = true
+	t.app.SetRoot(b.page, true)
+	t.app.SetFocus(b.notes)
+}
+
+// vaultName names a file in the lists: relative to the output folder when
+// it is in there, to the vault otherwise
+func (b *vaultBrowser) vaultName(rel string) string {
+	if b.vault.dir != "." {
+		if inside, ok := strings.CutPrefix(rel, b.vault.dir+"/"); ok {
+			return inside
+		}
+	}
+	return rel
+}
+
+// updateVaultList fills the note list after a scan or a filter change,
+// keeping the note shown if it passes the filter
+func (t *TUI) updateVaultList(b *vaultBrowser) {
+	th := t.theme
+	v := b.vault
+	keep := vaultFilters[b.filter].keep
+	b.shown = b.shown[:0]
+	var broken, orphans, untagged int
+	for _, rel := range v.order {
+		n := v.notes[rel]
+		if n.broken() > 0 {
+			broken++
+		}
+		if len(n.backlinks) == 0 {
+			orphans++
+		}
+		if len(n.tags) == 0 {
+			untagged++
+		}
+		if keep(n) {
+			b.shown = append(b.shown, rel)
+		}
+	}
+
+	current := b.current
+	b.notes.Clear()
+	for _, rel := range b.shown {
+		n := v.notes[rel]
+		role, icon := "success", "\uf15c"
+		switch {
+		case n.broken() > 0:
+			role, icon = "error", "\uf127"
+		case len(n.backlinks) == 0:
+			role = "warning"
+		}
+		b.notes.AddItem(fmt.Sprintf("%s%s%s %s %s→%d ←%d%s", th.Tag(role), icon, th.Tag("text"),
+			tview.Escape(b.vaultName(rel)), th.Tag("muted"), len(n.links), len(n.backlinks), th.Tag("text")), "", 0, nil)
+	}
+
+	title := fmt.Sprintf(" \uf02d %d notes ", len(v.order))
+	if b.filter > 0 {
+		title = fmt.Sprintf(" \uf02d %d/%d notes, %s ", len(b.shown), len(v.order), vaultFilters[b.filter].name)
+	}
+	if v.truncated {
+		title += th.Tag("warning") + "partial" + th.Tag("text") + " "
+	}
+	b.notes.SetTitle(title)
+	b.footer.SetTitle(fmt.Sprintf(" %s %sbroken links %d, no backlinks %d, no tags %d%s ",
+		tview.Escape(filepath.Join(v.root, filepath.FromSlash(v.dir))), th.Tag("muted"), broken, orphans, untagged, th.Tag("text")))
+	b.footer.SetTitleAlign(tview.AlignLeft)
+
+	if len(b.shown) == 0 {
+		b.current = ""
+		b.header.SetText(th.C("[muted]No note matches the filter.[text]"))
+		b.links.Clear()
+		b.targets = nil
+		b.body.SetText("")
+		return
+	}
+	index := 0
+	for i, rel := range b.shown {
+		if rel == current {
+			index = i
+		}
+	}
+	b.notes.SetCurrentItem(index)
+	t.loadNote(b, b.shown[index])
+}
+
+// selectNote shows a note in the list, dropping the filter if it hides it
+func (t *TUI) selectNote(b *vaultBrowser, rel string) {
+	if !containsString(b.shown, rel) {
+		b.filter = 0
+		b.current = rel
+		t.updateVaultList(b)
+	}
+	for i, shown := range b.shown {
+		if shown == rel {
+			b.notes.SetCurrentItem(i)
+		}
+	}
+	t.loadNote(b, rel)
+}
+
+// followLink goes to the note a link points to
+func (t *TUI) followLink(b *vaultBrowser, target string) {
+	switch _, isNote := b.vault.notes[target]; {
+	case target == "":
+		t.showToast("Broken link: no file in the vault matches it")
+	case target == b.current:
+		t.showToast("The link points into this note")
+	case !isNote:
+		t.showToast(b.vaultName(target) + " is outside the output folder")
+	default:
+		b.history = append(b.history, b.current)
+		t.selectNote(b, target)
+	}
+}
+
+// loadNote shows a note's frontmatter and its links, or the rendered note
+func (t *TUI) loadNote(b *vaultBrowser, rel string) {
+	th := t.theme
+	n, ok := b.vault.notes[rel]
+	if !ok {
+		return
+	}
+	b.current = rel
+
+	var header strings.Builder
+	fmt.Fprintf(&header, "[::b]%s[::-]  %s%s%s\n", tview.Escape(n.title), th.Tag("muted"), tview.Escape(rel), th.Tag("text"))
+	header.WriteString(th.Tag("label") + "tags" + th.Tag("text") + "     ")
+	if len(n.tags) == 0 {
+		header.WriteString(th.Tag("warning") + "none" + th.Tag("text"))
+	}
+	for _, tag := range n.tags {
+		header.WriteString(th.Tag("info") + "#" + tview.Escape(tag) + th.Tag("text") + " ")
+	}
+	header.WriteString("\n" + th.Tag("label") + "aliases" + th.Tag("text") + "  " + tview.Escape(strings.Join(n.aliases, ", ")) + "\n")
+	fmt.Fprintf(&header, "%slinks%s    %d out", th.Tag("label"), th.Tag("text"), len(n.links))
+	if broken := n.broken(); broken > 0 {
+		fmt.Fprintf(&header, ", %s%d broken%s", th.Tag("error"), broken, th.Tag("text"))
+	}
+	fmt.Fprintf(&header, ", %d backlinks", len(n.backlinks))
+	if len(b.history) > 0 {
+		fmt.Fprintf(&header, "   %sBackspace back to %s%s", th.Tag("muted"), tview.Escape(b.vaultName(b.history[len(b.history)-1])), th.Tag("text"))
+	}
+	b.header.SetText(header.String())
+	b.header.SetTitle(" " + tview.Escape(b.vaultName(rel)) + " ")
+
+	b.links.Clear()
+	b.targets = b.targets[:0]
+	for _, link := range n.links {
+		arrow, role, where := "→", "text", b.vaultName(link.target)
+		if link.embed {
+			arrow = "↳"
+		}
+		switch _, isNote := b.vault.notes[link.target]; {
+		case link.target == "":
+			role, where = "error", "not found"
+		case link.target == rel:
+			where = "this note"
+		case !isNote:
+			role = "muted"
+		}
+		b.links.AddItem(fmt.Sprintf("%s%s [[%s]]%s  %s%s%s", th.Tag(role), arrow, tview.Escape(link.raw), th.Tag("text"),
+			th.Tag("muted"), tview.Escape(where), th.Tag("text")), "", 0, nil)
+		b.targets = append(b.targets, link.target)
+	}
+	for _, from := range n.backlinks {
+		b.links.AddItem(fmt.Sprintf("%s← %s%s  %s%s%s", th.Tag("accent"), tview.Escape(b.vault.notes[from].title), th.Tag("text"),
+			th.Tag("muted"), tview.Escape(b.vaultName(from)), th.Tag("text")), "", 0, nil)
+		b.targets = append(b.targets, from)
+	}
+	b.links.SetTitle(fmt.Sprintf(" links %d out, %d in ", len(n.links), len(n.backlinks)))
+	if len(b.targets) == 0 {
+		b.links.SetTitle(" no links in or out ")
+	}
+
+	if b.showBody {
+		data, err := readHead(filepath.Join(b.vault.root, filepath.FromSlash(rel)), maxPreviewBytes)
+		if err != nil {
+			b.body.SetText(th.C("[error]") + tview.Escape(err.Error()) + th.Tag("text"))
+		} else {
+			_, body := splitFrontmatter(string(data))
+			b.body.SetText(t.renderMarkdown(body))
+		}
+		b.body.ScrollToBeginning()
+		b.right.SwitchToPage("body")
+	} else {
+		b.right.SwitchToPage("links")
+	}
+}
+
+// updateVaultFooter shows the keys of the vault page
+func (t *TUI) updateVaultFooter(b *vaultBrowser) {
+	view := "note"
+	if b.showBody {
+		view = "links"
+	}
+	next := vaultFilters[(b.filter+1)%len(vaultFilters)].name
+	b.footer.SetText(t.theme.C(fmt.Sprintf("[label]Enter[text] open/follow  [label]Backspace[text] back  [label]F[text] show %s  [label]P[text] %s  [label]R[text] rescan  [label]Tab[text] focus  [label]Esc[text] back", next, view)))
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCommonDir(t *testing.T) {
+	tests := []struct {
+		name string
+		dirs []string
+		want string
+	}{
+		{"none", nil, ""},
+		{"one", []string{"docs"}, "docs"},
+		{"siblings", []string{"docs/api", "docs/guide"}, "docs"},
+		{"nested", []string{"docs", "docs/api/v1"}, "docs"},
+		{"relative without a common parent", []string{"docs", "notes"}, ""},
+		{"dot", []string{".", "docs"}, ""},
+		{"only dot", []string{"."}, ""},
+		{"unclean", []string{"docs/./api/", "docs/guide/.."}, "docs"},
+		{"parent", []string{"../a", "../b"}, ".."},
+		{"parent and working directory", []string{"../a", "docs"}, ""},
+		{"absolute", []string{"/p/docs/api", "/p/docs/guide"}, "/p/docs"},
+		{"absolute under the root", []string{"/a", "/b"}, ""},
+		{"only the root", []string{"/"}, ""},
+		{"mixed", []string{"/p/docs", "docs"}, ""},
+		{"mixed relative first", []string{"docs", "/p/docs"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commonDir(tt.dirs); got != tt.want {
+				t.Errorf("commonDir(%q) = %q, want %q", tt.dirs, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVaultResolve(t *testing.T) {
+	root := t.TempDir()
+	for _, file := range []string{
+		".obsidian/app.json",
+		"docs/Index.md",
+		"docs/api/Client.md",
+		"docs/api/Overview.md",
+		"docs/guide/Overview.md",
+		"docs/guide/setup/Install.md",
+		"docs/Old.md",
+		"docs/archive/Old.md",
+		"assets/diagram.png",
+	} {
+		abs := filepath.Join(root, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(abs, []byte("# "+file+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	v, err := scanVault(filepath.Join(root, "docs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		from, raw, want string
+	}{
+		{"docs/Index.md", "Client", "docs/api/Client.md"},
+		{"docs/Index.md", "client", "docs/api/Client.md"},
+		{"docs/Index.md", "Client.md", "docs/api/Client.md"},
+		{"docs/Index.md", "Client|the client", "docs/api/Client.md"},
+		{"docs/Index.md", "Client#Usage", "docs/api/Client.md"},
+		{"docs/Index.md", "Client#^block", "docs/api/Client.md"},
+		{"docs/Index.md", "#Heading", "docs/Index.md"},
+		{"docs/Index.md", "docs/api/Client", "docs/api/Client.md"},
+		{"docs/Index.md", "/docs/api/Client", "docs/api/Client.md"},
+		{"docs/Index.md", "api/Client", "docs/api/Client.md"},
+		{"docs/api/Client.md", "Overview", "docs/api/Overview.md"},
+		// Neither in the note's folder: the shortest path, the first on a tie
+		{"docs/guide/setup/Install.md", "Overview", "docs/api/Overview.md"},
+		{"docs/guide/setup/Install.md", "Old", "docs/Old.md"},
+		{"docs/guide/Overview.md", "setup/Install", "docs/guide/setup/Install.md"},
+		{"docs/Index.md", "guide/Overview", "docs/guide/Overview.md"},
+		{"docs/Index.md", "diagram.png", "assets/diagram.png"},
+		{"docs/Index.md", "Missing", ""},
+		{"docs/Index.md", "ient", ""},
+	}
+	for _, tt := range tests {
+		if got := v.resolve(tt.from, tt.raw); got != tt.want {
+			t.Errorf("resolve(%q, %q) = %q, want %q", tt.from, tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestFrontmatterList(t *testing.T) {
+	tests := []struct {
+		name, front string
+		want        []string
+	}{
+		{"missing", "title: Notes", nil},
+		{"flow", "tags: [api, \"guide\", 'setup']", []string{"api", "guide", "setup"}},
+		{"empty flow", "tags: []", nil},
+		{"words", "tags: api guide", []string{"api", "guide"}},
+		{"commas", "tags: api,guide, setup", []string{"api", "guide", "setup"}},
+		{"block", "tags:\n  - api\n  - guide\ntitle: Notes", []string{"api", "guide"}},
+		{"block at column zero", "tags:\n- api\n- guide", []string{"api", "guide"}},
+		{"block ends at the next key", "tags:\n  - api\nalias:\n  - other", []string{"api"}},
+		{"other key", "aliases: [a]\ntags: [b]", []string{"b"}},
+		{"prefix of another key", "tagset: [a]", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := frontmatterList(tt.front, "tags"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("frontmatterList(%q) = %q, want %q", tt.front, got, tt.want)
+			}
+		})
+	}
+}